@@ -0,0 +1,486 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// AgentRegistration is sent to and comes from the API as a JSON
+type AgentRegistration struct {
+	Region   string `json:"region,omitempty"`
+	Name     string `json:"name"`
+	Hostname string `json:"hostname,omitempty"`
+	OS       string `json:"os,omitempty"`
+	Version  string `json:"version,omitempty"`
+}
+
+// AgentResult is a single check result pushed to the API by the agent
+type AgentResult struct {
+	CheckIdent string `json:"check_ident"`
+	Request
+}
+
+// AgentHealth is served by the agent health endpoint as a JSON
+type AgentHealth struct {
+	Status     string `json:"status"`
+	Region     string `json:"region"`
+	Name       string `json:"name"`
+	Checks     int    `json:"checks"`
+	Buffered   int    `json:"buffered"`
+	LastSynced string `json:"last_synced,omitempty"`
+	LastPushed string `json:"last_pushed,omitempty"`
+	LastError  string `json:"last_error,omitempty"`
+}
+
+// `agent` flags
+var (
+	agentFlagName         string
+	agentFlagBufferDir    string
+	agentFlagHealthListen string
+	agentFlagSyncInterval int
+)
+
+const (
+	agentPushInterval      = 10 * time.Second
+	agentMaxRetryInterval  = 5 * time.Minute
+	agentMaxBufferedResult = 100000
+	agentPushBatchSize     = 1000
+	agentBufferFile        = "results.ndjson"
+)
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+
+	agentCmd.Flags().StringVarP(&agentFlagName, "name", "n", "", "private region name, defaults to hostname")
+	agentCmd.Flags().StringVar(&agentFlagBufferDir, "buffer_dir", "", "where to buffer results while offline (default is $HOME/.binocs/agent)")
+	agentCmd.Flags().StringVar(&agentFlagHealthListen, "health_listen", "127.0.0.1:9390", "address for the /healthz endpoint; empty to disable")
+	agentCmd.Flags().IntVar(&agentFlagSyncInterval, "sync_interval", 60, "how often to pull assigned checks, in seconds")
+	agentCmd.Flags().SortFlags = false
+}
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run a private probe agent",
+	Long: `
+Run a self-hosted probe agent.
+
+The agent registers itself as a private region, pulls checks assigned to that region, runs them on their interval
+and pushes results to Binocs. Results are buffered on disk while Binocs is unreachable.
+
+Attach checks to the private region using "binocs check update --region <name>".
+`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+		spin.Disable()
+
+		if agentFlagSyncInterval < supportedIntervalMinimum {
+			handleErr(fmt.Errorf("Sync interval must be at least %d seconds", supportedIntervalMinimum))
+		}
+		if agentFlagName == "" {
+			agentFlagName = getUserHostname()
+		}
+		bufferDir := agentFlagBufferDir
+		if bufferDir == "" {
+			home, err := homedir.Dir()
+			if err != nil {
+				handleErr(err)
+			}
+			bufferDir = filepath.Join(home, storageDir, "agent")
+		}
+		err := os.MkdirAll(bufferDir, 0700)
+		if err != nil {
+			handleErr(err)
+		}
+
+		registration, err := registerAgent(agentFlagName)
+		if err != nil {
+			handleErr(err)
+		}
+		log.Printf("registered private region %s (%s)", registration.Name, registration.Region)
+
+		agent := newProbeAgent(registration, filepath.Join(bufferDir, agentBufferFile))
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if agentFlagHealthListen != "" {
+			go agent.serveHealth(ctx, agentFlagHealthListen)
+		}
+		agent.run(ctx, time.Duration(agentFlagSyncInterval)*time.Second)
+		log.Printf("agent stopped")
+	},
+}
+
+func registerAgent(name string) (AgentRegistration, error) {
+	registration := AgentRegistration{
+		Region:   viper.GetString("agent_region"),
+		Name:     name,
+		Hostname: getUserHostname(),
+		OS:       runtime.GOOS + "/" + runtime.GOARCH,
+		Version:  BinocsVersion,
+	}
+	postData, err := json.Marshal(registration)
+	if err != nil {
+		return registration, err
+	}
+	respData, err := util.BinocsAPI("/agents", http.MethodPost, postData)
+	if err != nil {
+		return registration, err
+	}
+	err = json.Unmarshal(respData, &registration)
+	if err != nil {
+		return registration, err
+	}
+	if registration.Region == "" {
+		return registration, fmt.Errorf("Error registering private region")
+	}
+	if viper.GetString("agent_region") != registration.Region {
+		viper.Set("agent_region", registration.Region)
+		err = viper.WriteConfigAs(viper.ConfigFileUsed())
+		if err != nil {
+			return registration, err
+		}
+	}
+	return registration, nil
+}
+
+type probeAgent struct {
+	mu           sync.Mutex
+	registration AgentRegistration
+	bufferPath   string
+	checks       map[string]Check
+	jobs         map[string]context.CancelFunc
+	queue        []AgentResult
+	results      chan AgentResult
+	lastSynced   time.Time
+	lastPushed   time.Time
+	lastError    error
+}
+
+func newProbeAgent(registration AgentRegistration, bufferPath string) *probeAgent {
+	return &probeAgent{
+		registration: registration,
+		bufferPath:   bufferPath,
+		checks:       map[string]Check{},
+		jobs:         map[string]context.CancelFunc{},
+		results:      make(chan AgentResult, 256),
+	}
+}
+
+func (a *probeAgent) run(ctx context.Context, syncInterval time.Duration) {
+	buffered, err := a.readBuffer()
+	if err != nil {
+		log.Printf("cannot read buffered results: %v", err)
+	}
+	a.queue = buffered
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.pushLoop(ctx)
+	}()
+
+	a.sync(ctx)
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			a.mu.Lock()
+			for _, cancel := range a.jobs {
+				cancel()
+			}
+			a.mu.Unlock()
+			wg.Wait()
+			return
+		case <-ticker.C:
+			a.sync(ctx)
+		}
+	}
+}
+
+// sync pulls checks assigned to the private region and (re)schedules the ones that changed
+func (a *probeAgent) sync(ctx context.Context) {
+	respData, err := util.BinocsAPI("/agents/"+a.registration.Region+"/checks", http.MethodGet, []byte{})
+	if err == nil {
+		checks := make([]Check, 0)
+		err = json.Unmarshal(respData, &checks)
+		if err == nil {
+			a.reschedule(ctx, checks)
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil {
+		a.lastError = err
+		log.Printf("cannot pull checks: %v", err)
+		return
+	}
+	a.lastSynced = time.Now()
+	a.lastError = nil
+}
+
+func (a *probeAgent) reschedule(ctx context.Context, checks []Check) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	assigned := map[string]bool{}
+	for _, c := range checks {
+		assigned[c.Ident] = true
+		current, ok := a.checks[c.Ident]
+		if ok && current.Updated == c.Updated {
+			continue
+		}
+		if cancel, ok := a.jobs[c.Ident]; ok {
+			cancel()
+		}
+		jobCtx, cancel := context.WithCancel(ctx)
+		a.checks[c.Ident] = c
+		a.jobs[c.Ident] = cancel
+		go a.runCheck(jobCtx, c)
+		log.Printf("scheduled check %s %s every %d s", c.Ident, c.Resource, c.Interval)
+	}
+	for ident, cancel := range a.jobs {
+		if !assigned[ident] {
+			cancel()
+			delete(a.jobs, ident)
+			delete(a.checks, ident)
+			log.Printf("unscheduled check %s", ident)
+		}
+	}
+}
+
+func (a *probeAgent) runCheck(ctx context.Context, check Check) {
+	interval := check.Interval
+	if interval < supportedIntervalMinimum {
+		interval = supportedIntervalMinimum
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		var result util.ProbeResult
+		if check.Protocol == protocolTCP {
+			result = util.ProbeTCP(check.Resource)
+		} else {
+			result = util.ProbeHTTP(check.Method, check.Resource)
+		}
+		request := makeProbeRequest(&check, &result)
+		request.Region = a.registration.Region
+		request.Status = statusDown
+		if result.Err == nil && (check.Protocol == protocolTCP || isUpStatusCode(result.StatusCode, check.UpCodes)) {
+			request.Status = statusUp
+		}
+		select {
+		case a.results <- AgentResult{CheckIdent: check.Ident, Request: request}:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pushLoop collects results and pushes them in batches, falling back to the disk buffer with a growing retry interval
+func (a *probeAgent) pushLoop(ctx context.Context) {
+	retryInterval := agentPushInterval
+	timer := time.NewTimer(agentPushInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case r := <-a.results:
+					a.enqueue(r)
+				default:
+					err := a.writeBuffer()
+					if err != nil {
+						log.Printf("cannot buffer results: %v", err)
+					}
+					return
+				}
+			}
+		case r := <-a.results:
+			a.enqueue(r)
+		case <-timer.C:
+			err := a.push()
+			if err != nil {
+				log.Printf("cannot push results, retrying in %v: %v", retryInterval, err)
+				err = a.writeBuffer()
+				if err != nil {
+					log.Printf("cannot buffer results: %v", err)
+				}
+				timer.Reset(retryInterval)
+				retryInterval = retryInterval * 2
+				if retryInterval > agentMaxRetryInterval {
+					retryInterval = agentMaxRetryInterval
+				}
+				continue
+			}
+			retryInterval = agentPushInterval
+			timer.Reset(agentPushInterval)
+		}
+	}
+}
+
+func (a *probeAgent) enqueue(r AgentResult) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.queue = append(a.queue, r)
+	if len(a.queue) > agentMaxBufferedResult {
+		a.queue = a.queue[len(a.queue)-agentMaxBufferedResult:]
+	}
+}
+
+// push sends the queue in batches; a batch leaves the queue only once the API accepts it, and the disk buffer
+// is rewritten with whatever remains. The queue is only changed by pushLoop, so it cannot shrink meanwhile
+func (a *probeAgent) push() error {
+	for {
+		a.mu.Lock()
+		batch := a.queue
+		a.mu.Unlock()
+		if len(batch) == 0 {
+			break
+		}
+		if len(batch) > agentPushBatchSize {
+			batch = batch[:agentPushBatchSize]
+		}
+		postData, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		_, respStatusCode, err := util.BinocsAPIWithStatus("/agents/"+a.registration.Region+"/results", http.MethodPost, postData)
+		if err == nil && (respStatusCode < 200 || respStatusCode > 299) {
+			err = fmt.Errorf("Binocs API responded with %d %s", respStatusCode, http.StatusText(respStatusCode))
+		}
+		a.mu.Lock()
+		if err != nil {
+			a.lastError = err
+			a.mu.Unlock()
+			return err
+		}
+		a.queue = a.queue[len(batch):]
+		a.lastPushed = time.Now()
+		a.lastError = nil
+		a.mu.Unlock()
+	}
+	return a.writeBuffer()
+}
+
+func (a *probeAgent) readBuffer() ([]AgentResult, error) {
+	results := []AgentResult{}
+	f, err := os.Open(a.bufferPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return results, nil
+		}
+		return results, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r AgentResult
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		results = append(results, r)
+	}
+	return results, scanner.Err()
+}
+
+// writeBuffer replaces the buffer file with the current queue, or removes it when the queue is empty
+func (a *probeAgent) writeBuffer() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.queue) == 0 {
+		err := os.Remove(a.bufferPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	tmpPath := a.bufferPath + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	encoder := json.NewEncoder(w)
+	for _, r := range a.queue {
+		if err = encoder.Encode(r); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, a.bufferPath)
+}
+
+func (a *probeAgent) health() (AgentHealth, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	health := AgentHealth{
+		Status:   "ok",
+		Region:   a.registration.Region,
+		Name:     a.registration.Name,
+		Checks:   len(a.checks),
+		Buffered: len(a.queue),
+	}
+	if !a.lastSynced.IsZero() {
+		health.LastSynced = a.lastSynced.Format(time.RFC3339)
+	}
+	if !a.lastPushed.IsZero() {
+		health.LastPushed = a.lastPushed.Format(time.RFC3339)
+	}
+	if a.lastError != nil {
+		health.Status = "offline"
+		health.LastError = a.lastError.Error()
+		return health, http.StatusServiceUnavailable
+	}
+	return health, http.StatusOK
+}
+
+func (a *probeAgent) serveHealth(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		health, statusCode := a.health()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_ = json.NewEncoder(w).Encode(health)
+	})
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	log.Printf("serving health endpoint at http://%s/healthz", addr)
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		log.Printf("health endpoint: %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	badgeMetricStatus       = "status"
+	badgeMetricUptime       = "uptime"
+	badgeMetricApdex        = "apdex"
+	badgeMetricMRT          = "mrt"
+	badgeColorLabel         = "#555"
+	badgeCharWidth          = 7
+	badgeTextPadding        = 10
+	badgePathPattern        = `^/badge/([a-f0-9]{7})/(status|uptime|apdex|mrt)\.svg$`
+	badgeMinRefreshInterval = 60
+)
+
+var badgeMetrics = []string{badgeMetricStatus, badgeMetricUptime, badgeMetricApdex, badgeMetricMRT}
+
+// `badge` flags
+var (
+	badgeFlagMetric          string
+	badgeFlagPeriod          string
+	badgeFlagLabel           string
+	badgeFlagOut             string
+	badgeFlagServe           string
+	badgeFlagRefreshInterval int
+)
+
+func init() {
+	rootCmd.AddCommand(badgeCmd)
+
+	badgeCmd.Flags().StringVar(&badgeFlagMetric, "metric", badgeMetricStatus, "badge metric, one of \"status\", \"uptime\", \"apdex\" or \"mrt\"")
+	badgeCmd.Flags().StringVarP(&badgeFlagPeriod, "period", "p", "month", "period of uptime, apdex and mrt badges")
+	badgeCmd.Flags().StringVar(&badgeFlagLabel, "label", "", "badge label, defaults to the metric name")
+	badgeCmd.Flags().StringVarP(&badgeFlagOut, "out", "o", "", "write badge to this file instead of standard output")
+	badgeCmd.Flags().StringVar(&badgeFlagServe, "serve", "", "serve badges of all checks at /badge/<ident>/<metric>.svg on this address, e.g. :8080")
+	badgeCmd.Flags().IntVar(&badgeFlagRefreshInterval, "refresh_interval", 300, "how often to refresh served badges, in seconds")
+}
+
+var badgeCmd = &cobra.Command{
+	Use:   "badge [check]",
+	Short: "Render SVG status and uptime badges",
+	Long: `
+Render an SVG badge with check status, uptime, Apdex or MRT, e.g. for a README.
+
+With --serve, badges of all checks are served at /badge/<ident>/<metric>.svg, from memory, refreshed every --refresh_interval seconds.
+`,
+	Example: `  binocs badge abcdef1 --metric uptime --period month --out badge.svg
+  binocs badge --serve :8080`,
+	Args:              cobra.MaximumNArgs(1),
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		match, err := regexp.MatchString(validPeriodPattern, badgeFlagPeriod)
+		if err != nil || !match {
+			handleErr(fmt.Errorf("Invalid period provided. Supported periods: hour, day, week, month"))
+		}
+
+		if len(badgeFlagServe) > 0 {
+			if len(args) > 0 {
+				handleErr(fmt.Errorf("Cannot render a single badge with --serve"))
+			}
+			if badgeFlagRefreshInterval < badgeMinRefreshInterval {
+				handleErr(fmt.Errorf("Refresh interval must be at least %d seconds", badgeMinRefreshInterval))
+			}
+			spin.Disable()
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			cache := &badgeCache{period: badgeFlagPeriod, badges: map[string][]byte{}}
+			go cache.run(ctx, time.Duration(badgeFlagRefreshInterval)*time.Second)
+			cache.serve(ctx, badgeFlagServe)
+			log.Printf("badge server stopped")
+			return
+		}
+
+		if len(args) == 0 {
+			handleErr(fmt.Errorf("Provide a check identifier, or use --serve"))
+		}
+		if !util.StringInSlice(badgeFlagMetric, badgeMetrics) {
+			handleErr(fmt.Errorf("Invalid metric provided, use one of: %s", strings.Join(badgeMetrics, ", ")))
+		}
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading badge...")
+
+		respData, err := util.BinocsAPI("/checks/"+args[0], http.MethodGet, []byte{})
+		if err != nil {
+			handleErr(err)
+		}
+		var check Check
+		err = json.Unmarshal(respData, &check)
+		if err != nil {
+			handleErr(err)
+		}
+		var metrics MetricsResponse
+		if badgeFlagMetric != badgeMetricStatus {
+			metrics, err = fetchMetrics(check.Ident, &url.Values{"period": []string{badgeFlagPeriod}})
+			if err != nil {
+				handleErr(err)
+			}
+		}
+		label := badgeFlagLabel
+		if len(label) == 0 {
+			label = badgeFlagMetric
+		}
+		value, color := badgeValue(check, metrics, badgeFlagMetric)
+		badge := drawBadge(label, value, color)
+		spin.Stop()
+
+		if len(badgeFlagOut) == 0 {
+			fmt.Println(badge)
+			return
+		}
+		err = os.WriteFile(badgeFlagOut, []byte(badge+"\n"), 0644)
+		if err != nil {
+			handleErr(err)
+		}
+		fmt.Println("Badge written to " + badgeFlagOut)
+	},
+}
+
+// badgeValue formats the metric and picks its color the same way the terminal output does
+func badgeValue(check Check, metrics MetricsResponse, metric string) (string, string) {
+	switch metric {
+	case badgeMetricStatus:
+		switch check.LastStatus {
+		case statusUp:
+			return "up", svgColorGreen
+		case statusDown:
+			return "down", svgColorRed
+		case statusStepUp, statusStepDown:
+			return strings.ToLower(statusName[check.LastStatus]), svgColorYellow
+		}
+	case badgeMetricUptime:
+		uptime, err := strconv.ParseFloat(metrics.Uptime, 32)
+		if err == nil {
+			return metrics.Uptime + "%", svgUptimeColor(uptime)
+		}
+	case badgeMetricApdex:
+		apdex, err := strconv.ParseFloat(metrics.Apdex, 32)
+		if err == nil {
+			switch {
+			case apdex >= 0.8:
+				return metrics.Apdex, svgColorGreen
+			case apdex >= 0.6:
+				return metrics.Apdex, svgColorYellow
+			}
+			return metrics.Apdex, svgColorRed
+		}
+	case badgeMetricMRT:
+		mrt, err := strconv.ParseFloat(metrics.MRT, 64)
+		if err == nil {
+			value := fmt.Sprintf("%.0f ms", mrt*1000)
+			switch {
+			case mrt <= check.Target:
+				return value, svgColorGreen
+			case mrt <= 4*check.Target:
+				return value, svgColorYellow
+			}
+			return value, svgColorRed
+		}
+	}
+	return "n/a", svgColorGrey
+}
+
+// drawBadge renders a flat shields.io-style badge; text widths are estimated, so that no fonts are needed
+func drawBadge(label, value, color string) string {
+	labelWidth := len([]rune(label))*badgeCharWidth + badgeTextPadding
+	valueWidth := len([]rune(value))*badgeCharWidth + badgeTextPadding
+	width := labelWidth + valueWidth
+	label = htmltemplate.HTMLEscapeString(label)
+	value = htmltemplate.HTMLEscapeString(value)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`+
+		`<title>%s: %s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%d" height="20" fill="%s"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`+
+		`<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text></g></svg>`,
+		width, label, value,
+		label, value,
+		width,
+		labelWidth, badgeColorLabel, labelWidth, valueWidth, color, width,
+		labelWidth/2, label, labelWidth/2, label,
+		labelWidth+valueWidth/2, value, labelWidth+valueWidth/2, value)
+}
+
+// badgeCache keeps rendered badges of all checks, keyed by "<ident>/<metric>"
+type badgeCache struct {
+	sync.RWMutex
+	period  string
+	badges  map[string][]byte
+	updated time.Time
+}
+
+func (c *badgeCache) run(ctx context.Context, interval time.Duration) {
+	c.refresh()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.refresh()
+		}
+	}
+}
+
+func (c *badgeCache) refresh() {
+	checks, err := fetchChecks(url.Values{})
+	if err != nil {
+		log.Printf("cannot refresh badges: %v", err)
+		return
+	}
+	badges := make(map[string][]byte)
+	for _, check := range checks {
+		metrics, err := fetchMetrics(check.Ident, &url.Values{"period": []string{c.period}})
+		if err != nil {
+			log.Printf("cannot refresh badges of check %s: %v", check.Ident, err)
+			for _, metric := range badgeMetrics {
+				if badge, _, ok := c.get(check.Ident, metric); ok {
+					badges[check.Ident+"/"+metric] = badge
+				}
+			}
+			continue
+		}
+		for _, metric := range badgeMetrics {
+			value, color := badgeValue(check, metrics, metric)
+			badges[check.Ident+"/"+metric] = []byte(drawBadge(metric, value, color))
+		}
+	}
+	c.Lock()
+	c.badges = badges
+	c.updated = time.Now()
+	c.Unlock()
+	log.Printf("refreshed badges of %d check(s)", len(checks))
+}
+
+func (c *badgeCache) get(ident, metric string) ([]byte, time.Time, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	badge, ok := c.badges[ident+"/"+metric]
+	return badge, c.updated, ok
+}
+
+func (c *badgeCache) serve(ctx context.Context, addr string) {
+	rx := regexp.MustCompile(badgePathPattern)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/badge/", func(w http.ResponseWriter, r *http.Request) {
+		m := rx.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			http.NotFound(w, r)
+			return
+		}
+		badge, updated, ok := c.get(m[1], m[2])
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Last-Modified", updated.UTC().Format(http.TimeFormat))
+		_, _ = w.Write(badge)
+	})
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	log.Printf("serving badges at http://%s/badge/<ident>/<metric>.svg", addr)
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		handleErr(err)
+	}
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/automato-io/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+const (
+	calendarDateLayout   = "2006-01-02"
+	calendarWorstDays    = 5
+	calendarCell         = "■"
+	calendarFirstYear    = 2020
+	calendarWeekdayWidth = 4
+)
+
+// DailyUptimeResponse comes from the API as a JSON; Date is a calendar day in the requested timezone
+type DailyUptimeResponse struct {
+	Date   string `json:"date"`
+	Uptime string `json:"uptime"`
+}
+
+// `check calendar` flags
+var (
+	checkCalendarFlagYear int
+)
+
+func init() {
+	checkCmd.AddCommand(checkCalendarCmd)
+
+	checkCalendarCmd.Flags().IntVar(&checkCalendarFlagYear, "year", 0, "display calendar for specified year, default current year")
+}
+
+var checkCalendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "View daily uptime of a check over a year",
+	Long: `
+View daily uptime of a check over a year, with each day colored the same way as uptime elsewhere.
+
+Days begin and end at midnight in your timezone. The worst days of the year are listed under the calendar, together with their incidents.
+`,
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading calendar...")
+
+		user, err := fetchUser()
+		if err != nil {
+			handleErr(err)
+		}
+		tz := userLocation(&user)
+		now := time.Now().In(tz)
+		year := checkCalendarFlagYear
+		if year == 0 {
+			year = now.Year()
+		}
+		if year < calendarFirstYear || year > now.Year() {
+			spin.Stop()
+			handleErr(fmt.Errorf("Invalid year provided: %d", year))
+		}
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, tz)
+		to := time.Date(year, time.December, 31, 0, 0, 0, 0, tz)
+
+		respData, err := util.BinocsAPI("/checks/"+args[0], http.MethodGet, []byte{})
+		if err != nil {
+			handleErr(err)
+		}
+		var check Check
+		err = json.Unmarshal(respData, &check)
+		if err != nil {
+			handleErr(err)
+		}
+
+		urlValues := url.Values{}
+		urlValues.Set("from", from.Format(calendarDateLayout))
+		urlValues.Set("to", to.Format(calendarDateLayout))
+		urlValues.Set("timezone", tz.String())
+		days, err := fetchDailyUptime(check.Ident, urlValues)
+		if err != nil {
+			handleErr(err)
+		}
+
+		incidentsURLValues := url.Values{}
+		incidentsURLValues.Set("check", check.Ident)
+		incidentsURLValues.Set("from", from.UTC().Format(time.RFC3339))
+		incidentsURLValues.Set("to", to.AddDate(0, 0, 1).UTC().Format(time.RFC3339))
+		incidents, err := fetchIncidents(incidentsURLValues)
+		if err != nil {
+			handleErr(err)
+		}
+
+		uptimes := make(map[string]string)
+		for _, d := range days {
+			uptimes[d.Date] = d.Uptime
+		}
+		calendar := drawCalendar(year, uptimes, now)
+		worstDays := drawCalendarWorstDays(days, incidents, tz)
+
+		columnDefinitions := []tableColumnDefinition{
+			{
+				Header:    "",
+				Priority:  1,
+				Alignment: tablewriter.ALIGN_LEFT,
+			},
+		}
+		var tableData [][]string
+		tableData = append(tableData, []string{drawChartTitle("UPTIME CALENDAR", calendar, strconv.Itoa(year))})
+		tableData = append(tableData, []string{colorBold.Sprint(check.Ident) + " " + check.Identity()})
+		tableData = append(tableData, []string{calendar})
+		tableData = append(tableData, []string{worstDays})
+		table := composeTable(tableData, columnDefinitions)
+		table.SetRowLine(true)
+		spin.Stop()
+		table.Render()
+	},
+}
+
+// drawCalendar lays the year out in week columns starting on Monday, with month names above them
+func drawCalendar(year int, uptimes map[string]string, now time.Time) string {
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location())
+	offset := (int(first.Weekday()) + 6) % 7
+	start := first.AddDate(0, 0, -offset)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, now.Location())
+	weeks := (offset+end.YearDay()-1)/7 + 1
+
+	var rows [7][]string
+	months := []rune(strings.Repeat(" ", weeks*2))
+	for w := 0; w < weeks; w++ {
+		for d := 0; d < 7; d++ {
+			day := start.AddDate(0, 0, w*7+d)
+			switch {
+			case day.Year() != year || day.After(now):
+				rows[d] = append(rows[d], " ")
+			default:
+				rows[d] = append(rows[d], formatCalendarCell(uptimes[day.Format(calendarDateLayout)]))
+			}
+			if day.Day() == 1 && day.Year() == year && w*2+3 <= len(months) {
+				copy(months[w*2:], []rune(day.Format("Jan")))
+			}
+		}
+	}
+
+	weekdays := [7]string{"Mon", "", "Wed", "", "Fri", "", "Sun"}
+	lines := []string{strings.Repeat(" ", calendarWeekdayWidth) + string(months[:weeks*2-1])}
+	for d := 0; d < 7; d++ {
+		label := weekdays[d] + strings.Repeat(" ", calendarWeekdayWidth-len(weekdays[d]))
+		lines = append(lines, colorFaint.Sprint(label)+strings.Join(rows[d], " "))
+	}
+	legend := colorFaint.Sprint(calendarCell) + " no data  " +
+		uptimeColor(100).Sprint(calendarCell) + " 100 %  " +
+		uptimeColor(99.95).Sprint(calendarCell) + " > 99.9 %  " +
+		uptimeColor(0).Sprint(calendarCell) + " <= 99.9 %"
+	lines = append(lines, "", strings.Repeat(" ", calendarWeekdayWidth)+legend)
+	return strings.Join(lines, "\n")
+}
+
+func formatCalendarCell(uptime string) string {
+	uptimeFloat, err := strconv.ParseFloat(uptime, 32)
+	if uptime == "" || err != nil {
+		return colorFaint.Sprint(calendarCell)
+	}
+	return uptimeColor(uptimeFloat).Sprint(calendarCell)
+}
+
+// drawCalendarWorstDays lists days below full uptime, from the worst, with incidents opened that day
+func drawCalendarWorstDays(days []DailyUptimeResponse, incidents []Incident, tz *time.Location) string {
+	type worstDay struct {
+		date   string
+		uptime float64
+		raw    string
+	}
+	var worst []worstDay
+	for _, d := range days {
+		uptimeFloat, err := strconv.ParseFloat(d.Uptime, 32)
+		if err != nil || uptimeFloat == 100.0 {
+			continue
+		}
+		worst = append(worst, worstDay{d.Date, uptimeFloat, d.Uptime})
+	}
+	sort.SliceStable(worst, func(i, j int) bool {
+		return worst[i].uptime < worst[j].uptime
+	})
+	if len(worst) > calendarWorstDays {
+		worst = worst[:calendarWorstDays]
+	}
+	if len(worst) == 0 {
+		return colorBold.Sprint("WORST DAYS") + "\n" + colorFaint.Sprint("no downtime")
+	}
+
+	dayIncidents := make(map[string][]string)
+	for _, v := range incidents {
+		opened, err := time.Parse(statusHistoryTimeLayout, v.Opened)
+		if err != nil {
+			continue
+		}
+		date := opened.In(tz).Format(calendarDateLayout)
+		dayIncidents[date] = append(dayIncidents[date], v.Ident)
+	}
+
+	lines := []string{colorBold.Sprint("WORST DAYS")}
+	for _, w := range worst {
+		date := w.date
+		if t, err := time.ParseInLocation(calendarDateLayout, w.date, tz); err == nil {
+			date = t.Format("Mon Jan 02")
+		}
+		incidentsSnippet := colorFaint.Sprint("no incidents")
+		if idents, ok := dayIncidents[w.date]; ok {
+			incidentsSnippet = "incidents " + strings.Join(idents, ", ")
+		}
+		var spacer string
+		if len(w.raw) < 7 {
+			spacer = strings.Repeat(" ", 7-len(w.raw))
+		}
+		lines = append(lines, date+"  "+formatUptime(w.raw)+spacer+"  "+incidentsSnippet)
+	}
+	lines = append(lines, colorFaint.Sprint("see `binocs incident inspect <id>` for details"))
+	return strings.Join(lines, "\n")
+}
+
+func fetchDailyUptime(ident string, urlValues url.Values) ([]DailyUptimeResponse, error) {
+	days := make([]DailyUptimeResponse, 0)
+	respData, err := util.BinocsAPI("/checks/"+ident+"/daily-uptime?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return days, err
+	}
+	decoder := json.NewDecoder(bytes.NewBuffer(respData))
+	err = decoder.Decode(&days)
+	return days, err
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,27 +16,38 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	util "github.com/automato-io/binocs-cli/util"
 	"github.com/automato-io/tablewriter"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 // Channel comes from the API as a JSON
 type Channel struct {
-	ID        int      `json:"id,omitempty"`
-	Ident     string   `json:"ident,omitempty"`
-	Type      string   `json:"type,omitempty"`
-	Alias     string   `json:"alias,omitempty"`
-	Handle    string   `json:"handle,omitempty"`
-	UsedCount int      `json:"used_count,omitempty"`
-	LastUsed  string   `json:"last_used,omitempty"`
-	Verified  string   `json:"verified,omitempty"`
-	Checks    []string `json:"checks,omitempty"`
+	ID        int             `json:"id,omitempty"`
+	Ident     string          `json:"ident,omitempty"`
+	Type      string          `json:"type,omitempty"`
+	Alias     string          `json:"alias,omitempty"`
+	Handle    string          `json:"handle,omitempty"`
+	UsedCount int             `json:"used_count,omitempty"`
+	LastUsed  string          `json:"last_used,omitempty"`
+	Verified  string          `json:"verified,omitempty"`
+	Checks    []string        `json:"checks,omitempty"`
+	Webhook   *WebhookConfig  `json:"webhook,omitempty"`
+	Opsgenie  *OpsgenieConfig `json:"opsgenie,omitempty"`
 }
 
 // Identity method returns "Type - Alias (handle)" or "handle"
 func (ch Channel) Identity() string {
 	// @todo remove in 0.8.x, alias always set
 	if len(ch.Alias) > 0 {
-		return ch.Alias + " (" + ch.Handle + ")"
+		return ch.Alias + " (" + ch.displayHandle() + ")"
+	}
+	return ch.displayHandle()
+}
+
+// displayHandle is the handle, with keys of PagerDuty and Opsgenie channels masked
+func (ch Channel) displayHandle() string {
+	if isOncallChannelType(ch.Type) {
+		return maskChannelKey(ch.Handle)
 	}
 	return ch.Handle
 }
@@ -50,12 +62,25 @@ var (
 	channelListFlagCheck string
 )
 
+// `channel inspect` flags
+var (
+	channelInspectFlagDeliveries int
+)
+
 // `channel add` flags
 var (
 	channelAddFlagAlias  string
 	channelAddFlagHandle string
 	channelAddFlagType   string
 	channelAddFlagAttach []string
+
+	channelAddFlagMethod      string
+	channelAddFlagHeader      []string
+	channelAddFlagPayload     string
+	channelAddFlagPayloadFile string
+	channelAddFlagSecret      string
+
+	channelAddFlagRegion string
 )
 
 // `channel attach` flags
@@ -74,23 +99,37 @@ var (
 var (
 	channelUpdateFlagAlias  string
 	channelUpdateFlagAttach []string
+
+	channelUpdateFlagMethod      string
+	channelUpdateFlagHeader      []string
+	channelUpdateFlagPayload     string
+	channelUpdateFlagPayloadFile string
+	channelUpdateFlagSecret      string
+
+	channelUpdateFlagRegion string
 )
 
 const (
 	validChannelIdentPattern      = `^[a-f0-9]{5}$`
 	validAliasPattern             = `^[\p{L}\p{N}_\s\/\-\.\(\)]{1,25}$`
-	validTypePattern              = `^(email|slack|telegram|sms)$`
+	validTypePattern              = `^(email|slack|telegram|sms|webhook|pagerduty|opsgenie)$`
 	validChannelsIdentListPattern = `^(all|([a-f0-9]{5})(,[a-f0-9]{5})*)$`
 	validNotificationTypePattern  = `^(response-change|status)$`
 	channelTypeEmail              = "email"
 	channelTypeSms                = "sms"
 	channelTypeSlack              = "slack"
 	channelTypeTelegram           = "telegram"
+	channelTypeWebhook            = "webhook"
+	channelTypePagerDuty          = "pagerduty"
+	channelTypeOpsgenie           = "opsgenie"
 )
 
 var validHandlePattern = map[string]string{
-	"email": `^(?:[a-z0-9!#$%&'*+/=?^_{|}~-]+(?:\.[a-z0-9!#$%&'*+/=?^_{|}~-]+)*|"(?:[\x01-\x08\x0b\x0c\x0e-\x1f\x21\x23-\x5b\x5d-\x7f]|\\[\x01-\x09\x0b\x0c\x0e-\x7f])*")@(?:(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z0-9](?:[a-z0-9-]*[a-z0-9])?|\[(?:(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.){3}(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?|[a-z0-9-]*[a-z0-9]:(?:[\x01-\x08\x0b\x0c\x0e-\x1f\x21-\x5a\x53-\x7f]|\\[\x01-\x09\x0b\x0c\x0e-\x7f])+)\])$`,
-	"sms":   `^\+?[1-9][0-9]{7,14}$`,
+	"email":     `^(?:[a-z0-9!#$%&'*+/=?^_{|}~-]+(?:\.[a-z0-9!#$%&'*+/=?^_{|}~-]+)*|"(?:[\x01-\x08\x0b\x0c\x0e-\x1f\x21\x23-\x5b\x5d-\x7f]|\\[\x01-\x09\x0b\x0c\x0e-\x7f])*")@(?:(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z0-9](?:[a-z0-9-]*[a-z0-9])?|\[(?:(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.){3}(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?|[a-z0-9-]*[a-z0-9]:(?:[\x01-\x08\x0b\x0c\x0e-\x1f\x21-\x5a\x53-\x7f]|\\[\x01-\x09\x0b\x0c\x0e-\x7f])+)\])$`,
+	"sms":       `^\+?[1-9][0-9]{7,14}$`,
+	"webhook":   validWebhookURLPattern,
+	"pagerduty": validPagerDutyRoutingKeyPattern,
+	"opsgenie":  validOpsgenieAPIKeyPattern,
 }
 
 func init() {
@@ -114,17 +153,31 @@ func init() {
 	channelDetachCmd.Flags().BoolVarP(&channelDetachFlagAll, "all", "a", false, "detach all checks from this channel")
 	channelDetachCmd.Flags().SortFlags = false
 
-	channelAddCmd.Flags().StringVarP(&channelAddFlagType, "type", "t", "", "channel type (E-mail, Slack, Telegram, SMS)")
-	channelAddCmd.Flags().StringVar(&channelAddFlagHandle, "handle", "", "channel handle - an address for \"E-mail\" channel type; a phone number for \"SMS\" channel type; handles for Slack and Telegram will be obtained programmatically")
+	channelAddCmd.Flags().StringVarP(&channelAddFlagType, "type", "t", "", "channel type (E-mail, Slack, Telegram, SMS, Webhook, PagerDuty, Opsgenie)")
+	channelAddCmd.Flags().StringVar(&channelAddFlagHandle, "handle", "", "channel handle - an address for \"E-mail\" channel type; a phone number for \"SMS\" channel type; a URL for \"Webhook\" channel type; an Events API v2 routing key for \"PagerDuty\" channel type; an API key for \"Opsgenie\" channel type; handles for Slack and Telegram will be obtained programmatically")
 	channelAddCmd.Flags().StringVar(&channelAddFlagAlias, "alias", "", "channel alias")
 	channelAddCmd.Flags().StringSliceVar(&channelAddFlagAttach, "attach", []string{}, "checks to attach to this channel (optional); can be either \"all\", or one or more check identifiers")
+	channelAddCmd.Flags().StringVar(&channelAddFlagMethod, "method", webhookDefaultMethod, "webhook HTTP method (POST, PUT, PATCH)")
+	channelAddCmd.Flags().StringSliceVar(&channelAddFlagHeader, "header", []string{}, "webhook HTTP header, e.g. \"Authorization=Bearer xyz\"; can be repeated")
+	channelAddCmd.Flags().StringVar(&channelAddFlagPayload, "payload", "", "webhook payload Go template; default JSON with incident and check fields")
+	channelAddCmd.Flags().StringVar(&channelAddFlagPayloadFile, "payload_file", "", "file with the webhook payload Go template")
+	channelAddCmd.Flags().StringVar(&channelAddFlagSecret, "secret", "", "webhook signing secret; generated if not provided")
+	channelAddCmd.Flags().StringVar(&channelAddFlagRegion, "region", "", "Opsgenie region (us, eu); default us")
 	channelAddCmd.Flags().SortFlags = false
 
 	channelsCmd.Flags().StringVarP(&channelListFlagCheck, "check", "c", "", "list only notification channels attached to a specific check")
 	channelListCmd.Flags().StringVarP(&channelListFlagCheck, "check", "c", "", "list only notification channels attached to a specific check")
 
+	channelInspectCmd.Flags().IntVar(&channelInspectFlagDeliveries, "deliveries", webhookDefaultDeliveries, "number of latest webhook delivery attempts to display")
+
 	channelUpdateCmd.Flags().StringVar(&channelUpdateFlagAlias, "alias", "", "channel alias")
 	channelUpdateCmd.Flags().StringSliceVar(&channelUpdateFlagAttach, "attach", []string{}, "checks to attach to this channel (optional); can be either \"all\", or one or more check identifiers")
+	channelUpdateCmd.Flags().StringVar(&channelUpdateFlagMethod, "method", "", "webhook HTTP method (POST, PUT, PATCH)")
+	channelUpdateCmd.Flags().StringSliceVar(&channelUpdateFlagHeader, "header", []string{}, "webhook HTTP header, e.g. \"Authorization=Bearer xyz\"; can be repeated, replaces all headers")
+	channelUpdateCmd.Flags().StringVar(&channelUpdateFlagPayload, "payload", "", "webhook payload Go template")
+	channelUpdateCmd.Flags().StringVar(&channelUpdateFlagPayloadFile, "payload_file", "", "file with the webhook payload Go template")
+	channelUpdateCmd.Flags().StringVar(&channelUpdateFlagSecret, "secret", "", "new webhook signing secret")
+	channelUpdateCmd.Flags().StringVar(&channelUpdateFlagRegion, "region", "", "Opsgenie region (us, eu)")
 	channelUpdateCmd.Flags().SortFlags = false
 }
 
@@ -151,8 +204,22 @@ var channelAddCmd = &cobra.Command{
 	Long: `
 Add a new notifications channel.
 
+Webhook channels send a request to the --handle URL on every notification. The payload is a Go template
+rendered with these fields:
+  .Event                 "incident_opened" or "incident_resolved"
+  .Incident              .Ident, .State, .Opened, .Closed, .Duration, .Note, .ResponseCodes
+  .Check                 .Ident, .Name, .Resource, .Protocol, .Method, .Status
+Use the json function to insert values into JSON payloads, e.g. {"name": {{json .Check.Name}}}, so that quotes
+and other special characters are escaped.
+Requests are signed with --secret; see "binocs webhook" for how to verify them.
+
+PagerDuty and Opsgenie channels trigger an alert when an incident is opened, and resolve it when the incident
+is resolved. Alerts are deduplicated with the key "binocs-<incident ID>", and incident notes are added to them.
+
 This command is interactive and asks user for parameters that were not provided as flags.
 `,
+	Example: `  binocs channel add --type webhook --alias ops --handle https://ops.example.com/binocs \
+    --header "Authorization=Bearer xyz" --payload '{"text": {{printf "%s is %s" .Check.Name .Check.Status | json}}}'`,
 	Aliases:           []string{"create"},
 	Args:              cobra.NoArgs,
 	DisableAutoGenTag: true,
@@ -366,7 +433,7 @@ This command is interactive and asks for confirmation.
 				continue
 			}
 			prompt := &survey.Confirm{
-				Message: "Delete " + respJSON.Type + " notification channel " + respJSON.Alias + " (" + respJSON.Handle + ")?",
+				Message: "Delete " + respJSON.Type + " notification channel " + respJSON.Alias + " (" + respJSON.displayHandle() + ")?",
 			}
 			var yes bool
 			err = survey.AskOne(prompt, &yes)
@@ -393,6 +460,8 @@ var channelInspectCmd = &cobra.Command{
 	Short: "View channel details",
 	Long: `
 View channel details and attached checks.
+
+For webhook channels, the latest delivery attempts and their response status codes are displayed, too.
 `,
 	Aliases:           []string{"view", "show", "info"},
 	Args:              cobra.ExactArgs(1),
@@ -419,7 +488,7 @@ View channel details and attached checks.
 		if respJSON.Type == channelTypeEmail && respJSON.Verified == "nil" {
 			handle = respJSON.Handle + " (unverified)"
 		} else {
-			handle = respJSON.Handle
+			handle = respJSON.displayHandle()
 		}
 		if respJSON.UsedCount > 0 {
 			lastUsed = respJSON.LastUsed
@@ -442,6 +511,15 @@ View channel details and attached checks.
 			colorBold.Sprint(`Handle: `) + handle + "\n" +
 			colorBold.Sprint(`Last used: `) + lastUsed + "\n" +
 			colorBold.Sprint(`Used: `) + used
+		if respJSON.Type == channelTypeWebhook && respJSON.Webhook != nil {
+			tableMainChannelCellContent += "\n" + composeWebhookCellContent(respJSON.Webhook)
+		}
+		if respJSON.Type == channelTypeOpsgenie && respJSON.Opsgenie != nil {
+			tableMainChannelCellContent += "\n" + colorBold.Sprint(`Region: `) + strings.ToUpper(respJSON.Opsgenie.Region)
+		}
+		if isOncallChannelType(respJSON.Type) {
+			tableMainChannelCellContent += "\n" + colorBold.Sprint(`Alert key: `) + incidentAlertKey("<incident ID>")
+		}
 
 		var tableMainChecksCellContent []string
 		if len(respJSON.Checks) > 0 {
@@ -477,11 +555,104 @@ View channel details and attached checks.
 		tableData = append(tableData, []string{tableMainChannelCellContent, strings.Join(tableMainChecksCellContent, "\n")})
 		tableMain := composeTable(tableData, columnDefinitions)
 
+		// Table "deliveries"
+
+		var tableDeliveries *tablewriter.Table
+		if respJSON.Type == channelTypeWebhook && channelInspectFlagDeliveries > 0 {
+			spin.Suffix = colorFaint.Sprint(" loading webhook deliveries...")
+			urlValues := url.Values{}
+			urlValues.Set("limit", strconv.Itoa(channelInspectFlagDeliveries))
+			deliveries, err := fetchWebhookDeliveries(respJSON.Ident, urlValues)
+			if err != nil {
+				handleErr(err)
+			}
+			tableDeliveries = composeDeliveriesTable(deliveries)
+		}
+
 		spin.Stop()
 		tableMain.Render()
+		if tableDeliveries != nil {
+			tableDeliveries.Render()
+		}
 	},
 }
 
+func composeWebhookCellContent(webhook *WebhookConfig) string {
+	var headers []string
+	for k := range webhook.Headers {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+	if len(headers) == 0 {
+		headers = []string{"-"}
+	}
+	payload := "default"
+	if len(webhook.Payload) > 0 {
+		payload = fmt.Sprintf("custom (%d characters)", len(webhook.Payload))
+	}
+	return colorBold.Sprint(`Method: `) + webhook.Method + "\n" +
+		colorBold.Sprint(`Headers: `) + strings.Join(headers, ", ") + "\n" +
+		colorBold.Sprint(`Payload: `) + payload + "\n" +
+		colorBold.Sprint(`Signed: `) + "HMAC-SHA256, " + webhookSignatureHeader
+}
+
+func composeDeliveriesTable(deliveries []WebhookDelivery) *tablewriter.Table {
+	var tableData [][]string
+	for _, d := range deliveries {
+		var status string
+		switch {
+		case len(d.Error) > 0:
+			status = color.New(color.FgRed).Sprint(d.Error)
+		case d.StatusCode >= 200 && d.StatusCode < 300:
+			status = color.New(color.FgGreen).Sprint(strconv.Itoa(d.StatusCode))
+		default:
+			status = color.New(color.FgRed).Sprint(strconv.Itoa(d.StatusCode))
+		}
+		tableData = append(tableData, []string{d.Time, d.Event, d.IncidentIdent, d.CheckIdent, strconv.Itoa(d.Attempt), status, d.Duration})
+	}
+	if len(tableData) == 0 {
+		tableData = append(tableData, []string{"-", "-", "-", "-", "-", "-", "-"})
+	}
+	columnDefinitions := []tableColumnDefinition{
+		{
+			Header:    "DELIVERED",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "EVENT",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "INCIDENT",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "CHECK",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "ATTEMPT",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "STATUS",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "DURATION",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+	}
+	return composeTable(tableData, columnDefinitions)
+}
+
 var channelListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all notification channels",
@@ -522,7 +693,7 @@ List all notification channels.
 			if v.Type == channelTypeEmail && v.Verified == "nil" {
 				handle = util.Ellipsis(v.Handle, 50) + " (unverified)"
 			} else {
-				handle = util.Ellipsis(v.Handle, 50)
+				handle = util.Ellipsis(v.displayHandle(), 50)
 			}
 			// @todo remove in 0.8.x, alias always set
 			if v.Alias == "" {
@@ -598,7 +769,7 @@ This command is interactive and asks user for parameters that were not provided
 
 func channelAddOrUpdate(mode string, channelIdent string) {
 	if mode != "add" && mode != "update" {
-		handleErr(fmt.Errorf("Unknown mode: " + mode))
+		handleErr(fmt.Errorf("Unknown mode: %s", mode))
 	}
 
 	var err error
@@ -606,10 +777,16 @@ func channelAddOrUpdate(mode string, channelIdent string) {
 	var tpl string
 
 	var (
-		flagAlias  string
-		flagHandle string
-		flagType   string
-		flagAttach []string
+		flagAlias       string
+		flagHandle      string
+		flagType        string
+		flagAttach      []string
+		flagMethod      string
+		flagHeader      []string
+		flagPayload     string
+		flagPayloadFile string
+		flagSecret      string
+		flagRegion      string
 	)
 
 	switch mode {
@@ -618,9 +795,21 @@ func channelAddOrUpdate(mode string, channelIdent string) {
 		flagHandle = channelAddFlagHandle
 		flagType = channelAddFlagType
 		flagAttach = channelAddFlagAttach
+		flagMethod = channelAddFlagMethod
+		flagHeader = channelAddFlagHeader
+		flagPayload = channelAddFlagPayload
+		flagPayloadFile = channelAddFlagPayloadFile
+		flagSecret = channelAddFlagSecret
+		flagRegion = channelAddFlagRegion
 	case "update":
 		flagAlias = channelUpdateFlagAlias
 		flagAttach = channelUpdateFlagAttach
+		flagMethod = channelUpdateFlagMethod
+		flagHeader = channelUpdateFlagHeader
+		flagPayload = channelUpdateFlagPayload
+		flagPayloadFile = channelUpdateFlagPayloadFile
+		flagSecret = channelUpdateFlagSecret
+		flagRegion = channelUpdateFlagRegion
 	}
 
 	var currentChannel Channel
@@ -648,7 +837,7 @@ func channelAddOrUpdate(mode string, channelIdent string) {
 		} else if !match {
 			prompt := &survey.Select{
 				Message: "Choose type:",
-				Options: []string{channelTypeEmail, channelTypeSlack, channelTypeTelegram, channelTypeSms},
+				Options: []string{channelTypeEmail, channelTypeSlack, channelTypeTelegram, channelTypeSms, channelTypeWebhook, channelTypePagerDuty, channelTypeOpsgenie},
 			}
 			err = survey.AskOne(prompt, &flagType)
 			if err != nil {
@@ -773,7 +962,52 @@ func channelAddOrUpdate(mode string, channelIdent string) {
 			}
 			spin.Stop()
 			fmt.Println("Successfully associated with Telegram.")
+		} else if flagType == channelTypeWebhook {
+			match, err = regexp.MatchString(validHandlePattern[channelTypeWebhook], flagHandle)
+			if err != nil {
+				handleErr(err)
+			} else if !match {
+				validate := func(val interface{}) error {
+					match, err = regexp.MatchString(validHandlePattern[channelTypeWebhook], val.(string))
+					if err != nil {
+						return err
+					} else if !match {
+						return errors.New("invalid URL format")
+					}
+					return nil
+				}
+				prompt := &survey.Input{
+					Message: "Enter a valid webhook URL:",
+				}
+				err = survey.AskOne(prompt, &flagHandle, survey.WithValidator(validate))
+				if err != nil {
+					handleErr(err)
+				}
+			}
+		} else if isOncallChannelType(flagType) {
+			flagHandle, err = askOncallChannelKey(flagType, flagHandle)
+			if err != nil {
+				handleErr(err)
+			}
+		}
+	}
+
+	var opsgenie *OpsgenieConfig
+	if flagType == channelTypeOpsgenie || currentChannel.Type == channelTypeOpsgenie {
+		opsgenie, err = composeOpsgenieConfig(mode == "update", currentChannel.Opsgenie, flagRegion)
+		if err != nil {
+			handleErr(err)
+		}
+	}
+
+	var webhook *WebhookConfig
+	var webhookSecretGenerated bool
+	if flagType == channelTypeWebhook || currentChannel.Type == channelTypeWebhook {
+		config, generated, err := composeWebhookConfig(mode == "update", currentChannel.Webhook, flagHandle, flagMethod, flagHeader, flagPayload, flagPayloadFile, flagSecret)
+		if err != nil {
+			handleErr(err)
 		}
+		webhook, webhookSecretGenerated = &config, generated
 	}
 
 	match, err = regexp.MatchString(validAliasPattern, flagAlias)
@@ -849,9 +1083,11 @@ func channelAddOrUpdate(mode string, channelIdent string) {
 	}
 
 	channel := Channel{
-		Alias:  flagAlias,
-		Handle: flagHandle,
-		Type:   flagType,
+		Alias:    flagAlias,
+		Handle:   flagHandle,
+		Type:     flagType,
+		Webhook:  webhook,
+		Opsgenie: opsgenie,
 	}
 	postData, err := json.Marshal(channel)
 	if err != nil {
@@ -883,9 +1119,9 @@ func channelAddOrUpdate(mode string, channelIdent string) {
 		var channelDescription string
 		// @todo remove in 0.8.x, alias always set
 		if len(channel.Alias) > 0 {
-			channelDescription = `"` + channel.Alias + `"` + " (" + channel.Handle + ")"
+			channelDescription = `"` + channel.Alias + `"` + " (" + channel.displayHandle() + ")"
 		} else {
-			channelDescription = `"` + channel.Handle + `"`
+			channelDescription = `"` + channel.displayHandle() + `"`
 		}
 		if mode == "add" {
 			tpl = channel.Type + " notifications channel " + channelDescription + " [" + channel.Ident + `] added successfully`
@@ -939,6 +1175,10 @@ func channelAddOrUpdate(mode string, channelIdent string) {
 	}
 	spin.Stop()
 	fmt.Println(tpl)
+	if webhookSecretGenerated {
+		fmt.Println("Webhook signing secret: " + webhook.Secret)
+		fmt.Println(colorFaint.Sprint("Store the secret now, it will not be displayed again; use it to verify the " + webhookSignatureHeader + " header"))
+	}
 }
 
 func fetchChannels(urlValues url.Values) ([]Channel, error) {
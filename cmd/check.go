@@ -5,13 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"testing"
 	"time"
 	"unicode/utf8"
 
@@ -39,6 +43,7 @@ type Check struct {
 	UpConfirmations            int      `json:"up_confirmations,omitempty"`
 	DownConfirmationsThreshold int      `json:"down_confirmations_threshold,omitempty"`
 	DownConfirmations          int      `json:"down_confirmations,omitempty"`
+	DownRegionsThreshold       int      `json:"down_regions_threshold,omitempty"`
 	LastChecked                string   `json:"last_checked,omitempty"`
 	LastStatus                 int      `json:"last_status,omitempty"`
 	LastStatusCode             string   `json:"last_status_code,omitempty"`
@@ -58,9 +63,14 @@ func (c Check) Identity() string {
 
 // MetricsResponse comes from the API as a JSON
 type MetricsResponse struct {
-	Apdex  string `json:"apdex"`
-	MRT    string `json:"mrt"`
-	Uptime string `json:"uptime"`
+	Apdex          string `json:"apdex"`
+	MRT            string `json:"mrt"`
+	P50            string `json:"p50,omitempty"`
+	P90            string `json:"p90,omitempty"`
+	P95            string `json:"p95,omitempty"`
+	P99            string `json:"p99,omitempty"`
+	Uptime         string `json:"uptime"`
+	LastStatusCode string `json:"last_status_code,omitempty"`
 }
 
 // ApdexResponse comes from the API as a JSON
@@ -96,9 +106,38 @@ type ResponseTimeHeatmapResponse struct {
 	To   string `json:"to"`
 }
 
+// ResponseTimeResponse comes from the API as a JSON; values are in seconds
+type ResponseTimeResponse struct {
+	MRT  string `json:"mrt"`
+	Min  string `json:"min"`
+	Max  string `json:"max"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TimingsResponse comes from the API as a JSON; phase durations are averages over the data point, in seconds
+type TimingsResponse struct {
+	Timings
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ResponseTimeHistogramResponse comes from the API as a JSON; Le is the bucket upper boundary in seconds, or "+Inf"
+type ResponseTimeHistogramResponse struct {
+	Le    string `json:"le"`
+	Count int    `json:"count"`
+}
+
 // RegionsResponse comes from the API as a JSON
 type RegionsResponse struct {
-	Regions []string `json:"regions"`
+	Regions        []string        `json:"regions"`
+	PrivateRegions []PrivateRegion `json:"private_regions,omitempty"`
+}
+
+// PrivateRegion is a region served by a self-hosted `binocs agent`
+type PrivateRegion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 // `check` flags
@@ -110,17 +149,32 @@ var (
 
 // `check ls` flags
 var (
-	checkListFlagPeriod string
-	checkListFlagRegion string
-	checkListFlagStatus string
-	checkListFlagWatch  bool
+	checkListFlagPeriod      string
+	checkListFlagFrom        string
+	checkListFlagTo          string
+	checkListFlagSince       string
+	checkListFlagRegion      string
+	checkListFlagStatus      string
+	checkListFlagPercentiles bool
+	checkListFlagWatch       bool
+	checkListFlagInterval    int
+	checkListFlagBell        bool
 )
 
 // `check inspect` flags
 var (
-	checkInspectFlagPeriod string
-	checkInspectFlagRegion string
-	checkInspectFlagWatch  bool
+	checkInspectFlagPeriod           string
+	checkInspectFlagFrom             string
+	checkInspectFlagTo               string
+	checkInspectFlagSince            string
+	checkInspectFlagCompare          string
+	checkInspectFlagRegion           string
+	checkInspectFlagWatch            bool
+	checkInspectFlagInterval         int
+	checkInspectFlagByRegion         bool
+	checkInspectFlagBands            bool
+	checkInspectFlagHistogram        bool
+	checkInspectFlagHistogramBuckets []float64
 )
 
 // `check add` flags
@@ -135,9 +189,19 @@ var (
 	checkAddFlagUpCodes                    string
 	checkAddFlagUpConfirmationsThreshold   int
 	checkAddFlagDownConfirmationsThreshold int
+	checkAddFlagDownRegionsThreshold       int
 	checkAddFlagAttach                     []string
 )
 
+// `check test` flags
+var (
+	checkTestFlagProtocol string
+	checkTestFlagResource string
+	checkTestFlagMethod   string
+	checkTestFlagTarget   float64
+	checkTestFlagUpCodes  string
+)
+
 // `check update` flags
 var (
 	checkUpdateFlagName                       string
@@ -148,6 +212,7 @@ var (
 	checkUpdateFlagUpCodes                    string
 	checkUpdateFlagUpConfirmationsThreshold   int
 	checkUpdateFlagDownConfirmationsThreshold int
+	checkUpdateFlagDownRegionsThreshold       int
 	checkUpdateFlagAttach                     []string
 )
 
@@ -161,7 +226,7 @@ const (
 	validMethodPattern                     = `^(GET|HEAD|POST|PUT|DELETE)$` // hardcoded; reflects supportedHTTPMethods
 	validUpCodePattern                     = `^([1-5]{1}[0-9]{2}-[1-5]{1}[0-9]{2}|([1-5]{1}(([0-9]{2}|[0-9]{1}x)|xx))){1}(,([1-5]{1}[0-9]{2}-[1-5]{1}[0-9]{2}|([1-5]{1}(([0-9]{2}|[0-9]{1}x)|xx))))*$`
 	validRegionPattern                     = `^[a-z0-9\-]{8,30}$`
-	validPeriodPattern                     = `^hour|day|week|month$`
+	validPeriodPattern                     = `^(hour|day|week|month)$`
 	validChecksIdentListPattern            = `^(all|([a-f0-9]{7})(,[a-f0-9]{7})*)$`
 	supportedConfirmationsThresholdMinimum = 1
 	supportedConfirmationsThresholdMaximum = 10
@@ -192,6 +257,11 @@ const (
 // 	http.MethodTrace:   false,
 // }
 
+// defaultHistogramBuckets are multiples of Target; 1 and 4 separate satisfied, tolerating and frustrated requests as in Apdex
+var defaultHistogramBuckets = []float64{0.25, 0.5, 1, 2, 4}
+
+const maxHistogramBuckets = 12
+
 var aggregateMetricsDataPoints = map[string]int{
 	periodHour:  60,
 	periodDay:   96,
@@ -200,7 +270,10 @@ var aggregateMetricsDataPoints = map[string]int{
 }
 
 func init() {
-	loadSupportedRegions()
+	// regions come from the API, which tests of the package cannot rely on
+	if !testing.Testing() {
+		loadSupportedRegions()
+	}
 
 	rootCmd.AddCommand(checksCmd)
 
@@ -211,6 +284,7 @@ func init() {
 	checkCmd.AddCommand(checkListCmd)
 	checkCmd.AddCommand(checkUpdateCmd)
 	checkCmd.AddCommand(checkDeleteCmd)
+	checkCmd.AddCommand(checkTestCmd)
 
 	checkCmd.Flags().StringVarP(&checkFlagPeriod, "period", "p", "day", "display values and charts for specified period")
 	checkCmd.Flags().StringVarP(&checkFlagRegion, "region", "r", "", "display values and charts from the specified region only")
@@ -226,21 +300,43 @@ func init() {
 	checkAddCmd.Flags().StringVarP(&checkAddFlagUpCodes, "up_codes", "", "200-302", "what are the good (\"up\") HTTP(S) response codes, e.g. `2xx` or `200-302`, or `200,301`")
 	checkAddCmd.Flags().IntVarP(&checkAddFlagUpConfirmationsThreshold, "up_confirmations_threshold", "", 2, "how many subsequent \"up\" responses before triggering notifications")
 	checkAddCmd.Flags().IntVarP(&checkAddFlagDownConfirmationsThreshold, "down_confirmations_threshold", "", 2, "how many subsequent \"down\" responses before triggering notifications")
+	checkAddCmd.Flags().IntVarP(&checkAddFlagDownRegionsThreshold, "down_regions_threshold", "", 1, "in how many regions a check must fail to be considered \"down\"")
 	checkAddCmd.Flags().StringSliceVar(&checkAddFlagAttach, "attach", []string{}, "channels to attach to this check (optional); can be either \"all\", or one or more channel identifiers")
 	checkAddCmd.Flags().SortFlags = false
 
-	checkInspectCmd.Flags().StringVarP(&checkInspectFlagPeriod, "period", "p", "day", "display values and charts for specified period")
+	checkInspectCmd.Flags().StringVarP(&checkInspectFlagPeriod, "period", "p", "", "display values and charts for specified period; default day")
+	checkInspectCmd.Flags().StringVar(&checkInspectFlagFrom, "from", "", "display values and charts from this time on, e.g. 2006-01-02T15:04, in your timezone")
+	checkInspectCmd.Flags().StringVar(&checkInspectFlagTo, "to", "", "display values and charts up to this time, e.g. 2006-01-02T15:04, in your timezone; default now")
+	checkInspectCmd.Flags().StringVar(&checkInspectFlagSince, "since", "", "display values and charts for a period ending now, e.g. 90m, 3h, 2d or 1w")
 	checkInspectCmd.Flags().StringVarP(&checkInspectFlagRegion, "region", "r", "", "display values and charts from the specified region only")
-	checkInspectCmd.Flags().BoolVar(&checkInspectFlagWatch, "watch", false, "run in cell view and refresh binocs output every 5 seconds")
-
-	checksCmd.Flags().StringVarP(&checkListFlagPeriod, "period", "p", "day", "display MRT, UPTIME, APDEX values and APDEX chart for specified period")
+	checkInspectCmd.Flags().StringVar(&checkInspectFlagCompare, "compare", "", "compare values and charts with the \"previous\" period, or with the period shifted back by a duration, e.g. 1d or 1w")
+	checkInspectCmd.Flags().BoolVar(&checkInspectFlagByRegion, "by-region", false, "display APDEX chart for each region in the regions table")
+	checkInspectCmd.Flags().BoolVar(&checkInspectFlagBands, "bands", false, "display min/max bands in the response time chart")
+	checkInspectCmd.Flags().BoolVar(&checkInspectFlagHistogram, "histogram", false, "display response time histogram")
+	checkInspectCmd.Flags().Float64SliceVar(&checkInspectFlagHistogramBuckets, "histogram_buckets", defaultHistogramBuckets, "histogram bucket upper boundaries, as multiples of the target response time")
+	checkInspectCmd.Flags().BoolVar(&checkInspectFlagWatch, "watch", false, "run in cell view and refresh binocs output every --interval seconds")
+	checkInspectCmd.Flags().IntVar(&checkInspectFlagInterval, "interval", defaultWatchInterval, "refresh interval of --watch, in seconds")
+
+	checksCmd.Flags().StringVarP(&checkListFlagPeriod, "period", "p", "", "display MRT, UPTIME, APDEX values and APDEX chart for specified period; default day")
+	checksCmd.Flags().StringVar(&checkListFlagFrom, "from", "", "display MRT, UPTIME, APDEX values and APDEX chart from this time on, e.g. 2006-01-02T15:04, in your timezone")
+	checksCmd.Flags().StringVar(&checkListFlagTo, "to", "", "display MRT, UPTIME, APDEX values and APDEX chart up to this time, e.g. 2006-01-02T15:04, in your timezone; default now")
+	checksCmd.Flags().StringVar(&checkListFlagSince, "since", "", "display MRT, UPTIME, APDEX values and APDEX chart for a period ending now, e.g. 90m, 3h, 2d or 1w")
 	checksCmd.Flags().StringVarP(&checkListFlagRegion, "region", "r", "", "display MRT, UPTIME, APDEX values and APDEX chart from the specified region only")
 	checksCmd.Flags().StringVarP(&checkListFlagStatus, "status", "s", "", "list only \"up\" or \"dow\" checks, default \"all\"")
-	checksCmd.Flags().BoolVar(&checkListFlagWatch, "watch", false, "run in cell view and refresh binocs output every 5 seconds")
-	checkListCmd.Flags().StringVarP(&checkListFlagPeriod, "period", "p", "day", "display MRT, UPTIME, APDEX values and APDEX chart for specified period")
+	checksCmd.Flags().BoolVar(&checkListFlagPercentiles, "percentiles", false, "display P50, P90, P95 and P99 response time columns")
+	checksCmd.Flags().BoolVar(&checkListFlagWatch, "watch", false, "run in cell view and refresh binocs output every --interval seconds")
+	checksCmd.Flags().IntVar(&checkListFlagInterval, "interval", defaultWatchInterval, "refresh interval of --watch, in seconds")
+	checksCmd.Flags().BoolVar(&checkListFlagBell, "bell", false, "ring the terminal bell when a check goes down, in --watch mode")
+	checkListCmd.Flags().StringVarP(&checkListFlagPeriod, "period", "p", "", "display MRT, UPTIME, APDEX values and APDEX chart for specified period; default day")
+	checkListCmd.Flags().StringVar(&checkListFlagFrom, "from", "", "display MRT, UPTIME, APDEX values and APDEX chart from this time on, e.g. 2006-01-02T15:04, in your timezone")
+	checkListCmd.Flags().StringVar(&checkListFlagTo, "to", "", "display MRT, UPTIME, APDEX values and APDEX chart up to this time, e.g. 2006-01-02T15:04, in your timezone; default now")
+	checkListCmd.Flags().StringVar(&checkListFlagSince, "since", "", "display MRT, UPTIME, APDEX values and APDEX chart for a period ending now, e.g. 90m, 3h, 2d or 1w")
 	checkListCmd.Flags().StringVarP(&checkListFlagRegion, "region", "r", "", "display MRT, UPTIME, APDEX values and APDEX chart from the specified region only")
 	checkListCmd.Flags().StringVarP(&checkListFlagStatus, "status", "s", "", "list only \"up\" or \"down\" checks, default \"all\"")
-	checkListCmd.Flags().BoolVar(&checkListFlagWatch, "watch", false, "run in cell view and refresh binocs output every 5 seconds")
+	checkListCmd.Flags().BoolVar(&checkListFlagPercentiles, "percentiles", false, "display P50, P90, P95 and P99 response time columns")
+	checkListCmd.Flags().BoolVar(&checkListFlagWatch, "watch", false, "run in cell view and refresh binocs output every --interval seconds")
+	checkListCmd.Flags().IntVar(&checkListFlagInterval, "interval", defaultWatchInterval, "refresh interval of --watch, in seconds")
+	checkListCmd.Flags().BoolVar(&checkListFlagBell, "bell", false, "ring the terminal bell when a check goes down, in --watch mode")
 
 	checkUpdateCmd.Flags().StringVarP(&checkUpdateFlagName, "name", "n", "", "check name")
 	checkUpdateCmd.Flags().StringVarP(&checkUpdateFlagMethod, "method", "m", "", "HTTP(S) method (GET, HEAD, POST, PUT, DELETE)")
@@ -250,8 +346,16 @@ func init() {
 	checkUpdateCmd.Flags().StringVarP(&checkUpdateFlagUpCodes, "up_codes", "", "", "what are the good (\"up\") HTTP(S) response codes, e.g. `2xx` or `200-302`, or `200,301`")
 	checkUpdateCmd.Flags().IntVarP(&checkUpdateFlagUpConfirmationsThreshold, "up_confirmations_threshold", "", 0, "how many subsequent \"up\" responses before triggering notifications")
 	checkUpdateCmd.Flags().IntVarP(&checkUpdateFlagDownConfirmationsThreshold, "down_confirmations_threshold", "", 0, "how many subsequent \"down\" responses before triggering notifications")
+	checkUpdateCmd.Flags().IntVarP(&checkUpdateFlagDownRegionsThreshold, "down_regions_threshold", "", 0, "in how many regions a check must fail to be considered \"down\"")
 	checkUpdateCmd.Flags().StringSliceVar(&checkUpdateFlagAttach, "attach", []string{}, "channels to attach to this check (optional); can be either \"all\", or one or more channel identifiers")
 	checkUpdateCmd.Flags().SortFlags = false
+
+	checkTestCmd.Flags().StringVarP(&checkTestFlagProtocol, "protocol", "p", "", "protocol (HTTP, HTTPS or TCP), derived from the resource when omitted")
+	checkTestCmd.Flags().StringVarP(&checkTestFlagResource, "resource", "r", "", "resource to check, a URL in case of HTTP(S), or HOSTNAME:PORT in case of TCP")
+	checkTestCmd.Flags().StringVarP(&checkTestFlagMethod, "method", "m", "GET", "HTTP(S) method (GET, HEAD, POST, PUT, DELETE)")
+	checkTestCmd.Flags().Float64VarP(&checkTestFlagTarget, "target", "t", 1.20, "response time that accommodates Apdex=1.0, in seconds with up to 3 decimal places")
+	checkTestCmd.Flags().StringVarP(&checkTestFlagUpCodes, "up_codes", "", "200-302", "what are the good (\"up\") HTTP(S) response codes, e.g. `2xx` or `200-302`, or `200,301`")
+	checkTestCmd.Flags().SortFlags = false
 }
 
 func isURL(str, protocol string) bool {
@@ -388,256 +492,436 @@ View check status and metrics.
 	Run: func(cmd *cobra.Command, args []string) {
 		util.VerifyAuthenticated()
 
-		if checkInspectFlagWatch {
-			runAsWatch()
-			return
-		}
-
-		var decoder *json.Decoder
-
-		urlValues := url.Values{
-			"period": []string{"day"},
-		}
-		periodTableTitle := "1 DAY"
-
-		match, err := regexp.MatchString(validPeriodPattern, checkInspectFlagPeriod)
-		if err == nil && match {
-			urlValues.Set("period", checkInspectFlagPeriod)
-			switch checkInspectFlagPeriod {
-			case "hour":
-				periodTableTitle = "1 HOUR"
-			case "day":
-				periodTableTitle = "1 DAY"
-			case "week":
-				periodTableTitle = "1 WEEK"
-			case "month":
-				periodTableTitle = "1 MONTH"
+		if checkInspectFlagHistogram {
+			err := validateHistogramBuckets(checkInspectFlagHistogramBuckets)
+			if err != nil {
+				handleErr(err)
 			}
 		}
 
-		match = isValidRegionAlias(checkInspectFlagRegion)
-		if len(checkInspectFlagRegion) > 0 && !match {
-			handleErr(fmt.Errorf("Invalid region provided. Supported regions: " + strings.Join(getSupportedRegionAliases(), ", ")))
-		} else if err == nil && match {
-			urlValues.Set("region", getRegionIdByAlias(checkInspectFlagRegion))
+		if len(checkInspectFlagRegion) > 0 && !isValidRegionAlias(checkInspectFlagRegion) {
+			handleErr(fmt.Errorf("Invalid region provided. Supported regions: %s", strings.Join(getSupportedRegionAliases(), ", ")))
 		}
 
-		spin.Start()
-		defer spin.Stop()
-		spin.Suffix = colorFaint.Sprint(" loading metrics...")
+		if checkInspectFlagWatch {
+			runAsWatch(func(w io.Writer) error {
+				return renderCheckInspect(w, args[0])
+			}, time.Duration(checkInspectFlagInterval)*time.Second)
+			return
+		}
 
-		user, err := fetchUser()
+		err := renderCheckInspect(os.Stdout, args[0])
 		if err != nil {
 			handleErr(err)
 		}
+	},
+}
 
-		respData, err := util.BinocsAPI("/checks/"+args[0], http.MethodGet, []byte{})
+// renderCheckInspect writes the `check inspect` tables to w
+func renderCheckInspect(w io.Writer, ident string) error {
+	var decoder *json.Decoder
+
+	urlValues := url.Values{}
+
+	if isValidRegionAlias(checkInspectFlagRegion) {
+		urlValues.Set("region", getRegionIdByAlias(checkInspectFlagRegion))
+	}
+
+	spin.Start()
+	defer spin.Stop()
+	spin.Suffix = colorFaint.Sprint(" loading metrics...")
+
+	user, err := fetchUser()
+	if err != nil {
+		return err
+	}
+
+	tr, err := parseTimeRange(checkInspectFlagPeriod, checkInspectFlagFrom, checkInspectFlagTo, checkInspectFlagSince, periodDay, userLocation(&user))
+	if err != nil {
+		return err
+	}
+	tr.setURLValues(&urlValues, true)
+	periodTableTitle := tr.title()
+	chartPeriodTitle := periodTableTitle
+
+	var compareURLValues *url.Values
+	var compareTitle string
+	if len(checkInspectFlagCompare) > 0 {
+		var compareRange timeRange
+		compareRange, compareTitle, err = parseCompareRange(tr, checkInspectFlagCompare)
 		if err != nil {
-			handleErr(err)
+			return err
 		}
-		var respJSON Check
-		err = json.Unmarshal(respData, &respJSON)
-		if err != nil {
-			handleErr(err)
+		compareURLValues = &url.Values{}
+		if urlValues.Has("region") {
+			compareURLValues.Set("region", urlValues.Get("region"))
 		}
+		compareRange.setURLValues(compareURLValues, true)
+		chartPeriodTitle = periodTableTitle + " VS " + compareTitle
+	}
 
-		metrics, err := fetchMetrics(respJSON.Ident, &urlValues)
-		if err != nil {
-			handleErr(err)
-		}
+	respData, err := util.BinocsAPI("/checks/"+ident, http.MethodGet, []byte{})
+	if err != nil {
+		return err
+	}
+	var respJSON Check
+	err = json.Unmarshal(respData, &respJSON)
+	if err != nil {
+		return err
+	}
 
-		// Table "main"
+	metrics, err := fetchMetrics(respJSON.Ident, &urlValues)
+	if err != nil {
+		return err
+	}
 
-		var resourceTitle, methodLine, responseLine, lastCheckedLine, upHTTPCodesLine, checkName, statusLine string
-		if respJSON.Protocol == protocolHTTP || respJSON.Protocol == protocolHTTPS {
-			resourceTitle = "URL"
-			methodLine = colorBold.Sprint("Method: ") + respJSON.Method + "\n"
-			if len(respJSON.LastStatusCode) > 0 {
-				responseLine = colorBold.Sprint("Response: ") + respJSON.LastStatusCode + "\n"
-			} else {
-				responseLine = colorBold.Sprint("Response: ") + "[waiting for data]" + "\n"
-			}
-			upHTTPCodesLine = colorBold.Sprint("UP HTTP Codes: ") + respJSON.UpCodes + "\n"
-		}
-		if respJSON.Protocol == protocolICMP || respJSON.Protocol == protocolTCP {
-			resourceTitle = "Host"
+	var compareMetrics MetricsResponse
+	if compareURLValues != nil {
+		compareMetrics, err = fetchMetrics(respJSON.Ident, compareURLValues)
+		if err != nil {
+			return err
 		}
+	}
+
+	// Table "main"
 
-		if respJSON.Name == "" {
-			checkName = "-"
+	var resourceTitle, methodLine, responseLine, lastCheckedLine, upHTTPCodesLine, checkName, statusLine string
+	if respJSON.Protocol == protocolHTTP || respJSON.Protocol == protocolHTTPS {
+		resourceTitle = "URL"
+		methodLine = colorBold.Sprint("Method: ") + respJSON.Method + "\n"
+		if len(respJSON.LastStatusCode) > 0 {
+			responseLine = colorBold.Sprint("Response: ") + respJSON.LastStatusCode + "\n"
 		} else {
-			checkName = respJSON.Name
+			responseLine = colorBold.Sprint("Response: ") + "[waiting for data]" + "\n"
 		}
+		upHTTPCodesLine = colorBold.Sprint("UP HTTP Codes: ") + respJSON.UpCodes + "\n"
+	}
+	if respJSON.Protocol == protocolICMP || respJSON.Protocol == protocolTCP {
+		resourceTitle = "Host"
+	}
 
-		if respJSON.LastChecked != "nil" {
-			lastChecked, err := time.Parse("2006-01-02 15:04:05 -0700", respJSON.LastChecked)
-			if err == nil {
-				lastCheckedLine = colorBold.Sprint("Last checked: ") + time.Since(lastChecked).Round(1*time.Second).String() + " ago"
-			}
-		}
+	if respJSON.Name == "" {
+		checkName = "-"
+	} else {
+		checkName = respJSON.Name
+	}
 
-		if respJSON.LastStatus == statusUnknown {
-			statusLine = ""
-		} else {
-			statusLine = colorBold.Sprint("Status: ") + formatStatus(&respJSON) + "\n"
+	if respJSON.LastChecked != "nil" {
+		lastChecked, err := time.Parse("2006-01-02 15:04:05 -0700", respJSON.LastChecked)
+		if err == nil {
+			lastCheckedLine = colorBold.Sprint("Last checked: ") + time.Since(lastChecked).Round(1*time.Second).String() + " ago"
 		}
+	}
 
-		if user.CreditBalance == 0 {
-			statusLine = colorBold.Sprint("Status: ") + color.YellowString(statusName[statusUnknown]) + "\n"
-			responseLine = colorBold.Sprint("Response: ") + "n/a" + "\n"
-		}
+	if respJSON.LastStatus == statusUnknown {
+		statusLine = ""
+	} else {
+		statusLine = colorBold.Sprint("Status: ") + formatStatus(&respJSON) + "\n"
+	}
 
-		tableMainCheckCellContent := colorBold.Sprint(`ID: `) + respJSON.Ident + "\n" +
-			colorBold.Sprint(`Name: `) + checkName + "\n" +
-			colorBold.Sprint(resourceTitle+`: `) + respJSON.Resource + "\n" +
-			methodLine +
-			statusLine +
-			responseLine +
-			lastCheckedLine
+	if user.CreditBalance == 0 {
+		statusLine = colorBold.Sprint("Status: ") + color.YellowString(statusName[statusUnknown]) + "\n"
+		responseLine = colorBold.Sprint("Response: ") + "n/a" + "\n"
+	}
 
-		uptimeValue := formatUptime(metrics.Uptime)
-		apdexValue := formatApdex(metrics.Apdex)
-		mrtValue := formatMRT(metrics.MRT)
-		if uptimeValue == "n/a" && apdexValue == "n/a" && mrtValue == "n/a" {
-			uptimeValue = "[waiting for data]"
-			apdexValue = "[waiting for data]"
-			mrtValue = "[waiting for data]"
-		}
+	tableMainCheckCellContent := colorBold.Sprint(`ID: `) + respJSON.Ident + "\n" +
+		colorBold.Sprint(`Name: `) + checkName + "\n" +
+		colorBold.Sprint(resourceTitle+`: `) + respJSON.Resource + "\n" +
+		methodLine +
+		statusLine +
+		responseLine +
+		lastCheckedLine
 
-		if user.CreditBalance == 0 {
-			uptimeValue = "n/a"
-			apdexValue = "n/a"
-			mrtValue = "n/a"
-		}
+	uptimeValue := formatUptime(metrics.Uptime)
+	apdexValue := formatApdex(metrics.Apdex)
+	mrtValue := formatMRT(metrics.MRT)
+	if uptimeValue == "n/a" && apdexValue == "n/a" && mrtValue == "n/a" {
+		uptimeValue = "[waiting for data]"
+		apdexValue = "[waiting for data]"
+		mrtValue = "[waiting for data]"
+	}
 
-		tableMainMetricsCellContent := colorBold.Sprint(`Uptime: `) + uptimeValue + "\n" +
-			colorBold.Sprint(`Apdex: `) + apdexValue + "\n" +
-			colorBold.Sprint(`MRT: `) + mrtValue
+	if user.CreditBalance == 0 {
+		uptimeValue = "n/a"
+		apdexValue = "n/a"
+		mrtValue = "n/a"
+	}
 
-		regions := ""
-		for i, v := range respJSON.Regions {
-			if len(regions) > 0 {
-				if i%4 == 0 {
-					regions = regions + ",\n" + regionAliases[v]
-				} else {
-					regions = regions + ", " + regionAliases[v]
-				}
+	if compareURLValues != nil && user.CreditBalance > 0 {
+		uptimeValue = uptimeValue + " " + formatMetricDelta(metrics.Uptime, compareMetrics.Uptime, 2, "", true)
+		apdexValue = apdexValue + " " + formatMetricDelta(metrics.Apdex, compareMetrics.Apdex, 2, "", true)
+		mrtValue = mrtValue + " " + formatMetricDelta(metrics.MRT, compareMetrics.MRT, 3, " s", false)
+	}
+
+	percentilesValue := formatPercentile(metrics.P50, respJSON.Target) + " / " + formatPercentile(metrics.P90, respJSON.Target) + " / " +
+		formatPercentile(metrics.P95, respJSON.Target) + " / " + formatPercentile(metrics.P99, respJSON.Target)
+	if user.CreditBalance == 0 {
+		percentilesValue = "n/a"
+	}
+
+	tableMainMetricsCellContent := colorBold.Sprint(`Uptime: `) + uptimeValue + "\n" +
+		colorBold.Sprint(`Apdex: `) + apdexValue + "\n" +
+		colorBold.Sprint(`MRT: `) + mrtValue + "\n" +
+		colorBold.Sprint(`P50/90/95/99: `) + percentilesValue
+	if compareURLValues != nil {
+		tableMainMetricsCellContent = tableMainMetricsCellContent + "\n" + colorFaint.Sprint("vs "+strings.ToLower(compareTitle))
+	}
+
+	regions := ""
+	for i, v := range respJSON.Regions {
+		if len(regions) > 0 {
+			if i%4 == 0 {
+				regions = regions + ",\n" + regionAliases[v]
 			} else {
-				regions = regionAliases[v]
+				regions = regions + ", " + regionAliases[v]
 			}
+		} else {
+			regions = regionAliases[v]
+		}
+	}
+
+	tableMainSettingsCellContent := colorBold.Sprint(`Checking interval: `) + strconv.Itoa(respJSON.Interval) + ` s ` + "\n" +
+		upHTTPCodesLine +
+		colorBold.Sprint(`Target response time: `) + fmt.Sprintf("%.3f s", respJSON.Target) + "\n" +
+		colorBold.Sprint(`Thresholds: `) + `UP - ` + strconv.Itoa(respJSON.UpConfirmationsThreshold) + `, DOWN - ` + strconv.Itoa(respJSON.DownConfirmationsThreshold) + "\n" +
+		colorBold.Sprint(`Region quorum: `) + formatDownRegionsThreshold(&respJSON) + "\n" +
+		colorBold.Sprint(`Binocs regions: `) + regions
+
+	tableMainColumnDefinitions := []tableColumnDefinition{
+		{
+			Header:    "METRICS (" + periodTableTitle + ")",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "CHECK",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "SETTINGS",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+	}
+
+	var tableMainData [][]string
+	tableMainData = append(tableMainData, []string{tableMainMetricsCellContent, tableMainCheckCellContent, tableMainSettingsCellContent})
+	tableMain := composeTableTo(w, tableMainData, tableMainColumnDefinitions)
+
+	// Table "regions"
+
+	var tableRegions *tablewriter.Table
+	if !urlValues.Has("region") && len(respJSON.Regions) > 1 {
+		spin.Suffix = colorFaint.Sprint(" loading region metrics...")
+		ch := make(chan tableRow)
+		for _, r := range respJSON.Regions {
+			go makeRegionBreakdownRow(respJSON, r, urlValues, checkInspectFlagByRegion, user.CreditBalance == 0, ch)
+		}
+		var tableRegionsData [][]string
+		for range respJSON.Regions {
+			row := <-ch
+			if row.err != nil {
+				err = row.err
+				continue
+			}
+			tableRegionsData = append(tableRegionsData, row.cells)
 		}
-
-		tableMainSettingsCellContent := colorBold.Sprint(`Checking interval: `) + strconv.Itoa(respJSON.Interval) + ` s ` + "\n" +
-			upHTTPCodesLine +
-			colorBold.Sprint(`Target response time: `) + fmt.Sprintf("%.3f s", respJSON.Target) + "\n" +
-			colorBold.Sprint(`Thresholds: `) + `UP - ` + strconv.Itoa(respJSON.UpConfirmationsThreshold) + `, DOWN - ` + strconv.Itoa(respJSON.DownConfirmationsThreshold) + "\n" +
-			colorBold.Sprint(`Binocs regions: `) + regions
-
-		tableMainColumnDefinitions := []tableColumnDefinition{
+		if err != nil {
+			return err
+		}
+		sort.Slice(tableRegionsData, func(i, j int) bool {
+			return tableRegionsData[i][0] < tableRegionsData[j][0]
+		})
+		tableRegionsColumnDefinitions := []tableColumnDefinition{
 			{
-				Header:    "METRICS (" + periodTableTitle + ")",
+				Header:    "REGION",
 				Priority:  1,
 				Alignment: tablewriter.ALIGN_LEFT,
 			},
 			{
-				Header:    "CHECK",
+				Header:    "HTTP",
+				Priority:  2,
+				Alignment: tablewriter.ALIGN_RIGHT,
+			},
+			{
+				Header:    "MRT",
 				Priority:  1,
-				Alignment: tablewriter.ALIGN_LEFT,
+				Alignment: tablewriter.ALIGN_RIGHT,
 			},
 			{
-				Header:    "SETTINGS",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_LEFT,
+				Header:    "UPTIME",
+				Priority:  1,
+				Alignment: tablewriter.ALIGN_RIGHT,
 			},
-		}
-
-		var tableMainData [][]string
-		tableMainData = append(tableMainData, []string{tableMainMetricsCellContent, tableMainCheckCellContent, tableMainSettingsCellContent})
-		tableMain := composeTable(tableMainData, tableMainColumnDefinitions)
-
-		// Combined table
-
-		tableChartsColumnDefinitions := []tableColumnDefinition{
 			{
-				Header:    "",
+				Header:    "APDEX",
 				Priority:  1,
-				Alignment: tablewriter.ALIGN_LEFT,
+				Alignment: tablewriter.ALIGN_RIGHT,
 			},
 		}
+		if checkInspectFlagByRegion {
+			tableRegionsColumnDefinitions = append(tableRegionsColumnDefinitions, tableColumnDefinition{
+				Header:    "APDEX " + periodTableTitle,
+				Priority:  2,
+				Alignment: tablewriter.ALIGN_RIGHT,
+			})
+		}
+		if respJSON.Protocol != protocolHTTP && respJSON.Protocol != protocolHTTPS {
+			tableRegionsColumnDefinitions[1].hidden = true
+		}
+		tableRegions = composeTableTo(w, tableRegionsData, tableRegionsColumnDefinitions)
+	}
 
-		var tableChartsData [][]string
+	// Combined table
 
-		// Sub-table "http response codes"
+	tableChartsColumnDefinitions := []tableColumnDefinition{
+		{
+			Header:    "",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+	}
 
-		if respJSON.Protocol == protocolHTTP || respJSON.Protocol == protocolHTTPS {
-			responseCodesData, err := util.BinocsAPI("/checks/"+respJSON.Ident+"/response-codes?"+urlValues.Encode(), http.MethodGet, []byte{})
-			if err != nil {
-				handleErr(err)
-			}
-			responseCodes := make([]ResponseCodesResponse, 0)
-			decoder = json.NewDecoder(bytes.NewBuffer(responseCodesData))
-			err = decoder.Decode(&responseCodes)
+	var tableChartsData [][]string
+
+	// Sub-table "http response codes"
+
+	if respJSON.Protocol == protocolHTTP || respJSON.Protocol == protocolHTTPS {
+		responseCodes, err := fetchResponseCodes(respJSON.Ident, &urlValues)
+		if err != nil {
+			return err
+		}
+		var compareResponseCodes []ResponseCodesResponse
+		if compareURLValues != nil {
+			compareResponseCodes, err = fetchResponseCodes(respJSON.Ident, compareURLValues)
 			if err != nil {
-				handleErr(err)
+				return err
 			}
-
-			responseCodesChart := drawResponseCodesChart(responseCodes, aggregateMetricsDataPoints[urlValues.Get("period")], respJSON.UpCodes, 16)
-			responseCodesChartTitle := drawChartTitle("HTTP RESPONSE CODES", responseCodesChart, periodTableTitle)
-			tableChartsData = append(tableChartsData, []string{responseCodesChartTitle})
-			tableChartsData = append(tableChartsData, []string{responseCodesChart})
 		}
 
-		// Sub-table "apdex trend"
+		responseCodesChart := drawResponseCodesChart(responseCodes, compareResponseCodes, tr.dataPoints(), respJSON.UpCodes, 16)
+		responseCodesChartTitle := drawChartTitle("HTTP RESPONSE CODES", responseCodesChart, chartPeriodTitle)
+		tableChartsData = append(tableChartsData, []string{responseCodesChartTitle})
+		tableChartsData = append(tableChartsData, []string{responseCodesChart})
+	}
+
+	// Sub-table "apdex trend"
 
-		apdexData, err := util.BinocsAPI("/checks/"+respJSON.Ident+"/apdex?"+urlValues.Encode(), http.MethodGet, []byte{})
-		if err != nil {
-			handleErr(err)
-		}
-		apdex := make([]ApdexResponse, 0)
-		decoder = json.NewDecoder(bytes.NewBuffer(apdexData))
-		err = decoder.Decode(&apdex)
+	apdex, err := fetchApdex(respJSON.Ident, &urlValues)
+	if err != nil {
+		return err
+	}
+	var compareApdex []ApdexResponse
+	if compareURLValues != nil {
+		compareApdex, err = fetchApdex(respJSON.Ident, compareURLValues)
 		if err != nil {
-			handleErr(err)
+			return err
 		}
+	}
+
+	apdexChart := drawApdexChart(apdex, compareApdex, tr.dataPoints(), "      ")
+	apdexChartTitle := drawChartTitle("APDEX TREND", apdexChart, chartPeriodTitle)
+	tableChartsData = append(tableChartsData, []string{apdexChartTitle})
+	tableChartsData = append(tableChartsData, []string{apdexChart})
+
+	// Sub-table "response time"
+
+	responseTimeData, err := util.BinocsAPI("/checks/"+respJSON.Ident+"/response-time?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return err
+	}
+	responseTime := make([]ResponseTimeResponse, 0)
+	decoder = json.NewDecoder(bytes.NewBuffer(responseTimeData))
+	err = decoder.Decode(&responseTime)
+	if err != nil {
+		return err
+	}
 
-		apdexChart := drawApdexChart(apdex, aggregateMetricsDataPoints[urlValues.Get("period")], "      ")
-		apdexChartTitle := drawChartTitle("APDEX TREND", apdexChart, periodTableTitle)
-		tableChartsData = append(tableChartsData, []string{apdexChartTitle})
-		tableChartsData = append(tableChartsData, []string{apdexChart})
+	responseTimeChart := drawResponseTimeChart(responseTime, tr.dataPoints(), respJSON.Target, checkInspectFlagBands, "")
+	responseTimeChartTitle := drawChartTitle("RESPONSE TIME", responseTimeChart, periodTableTitle)
+	tableChartsData = append(tableChartsData, []string{responseTimeChartTitle})
+	tableChartsData = append(tableChartsData, []string{responseTimeChart})
 
-		// Sub-table "response times heatmap"
+	// Sub-table "response times heatmap"
 
-		responseTimeHeatmapData, err := util.BinocsAPI("/checks/"+respJSON.Ident+"/response-time-heatmap?"+urlValues.Encode(), http.MethodGet, []byte{})
+	responseTimeHeatmapData, err := util.BinocsAPI("/checks/"+respJSON.Ident+"/response-time-heatmap?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return err
+	}
+	responseTimeHeatmap := make([]ResponseTimeHeatmapResponse, 0)
+	decoder = json.NewDecoder(bytes.NewBuffer(responseTimeHeatmapData))
+	err = decoder.Decode(&responseTimeHeatmap)
+	if err != nil {
+		return err
+	}
+
+	responseTimeHeatmapChart := drawResponseTimeHeatmapChart(responseTimeHeatmap, tr.dataPoints(), respJSON.Target, "")
+	responseTimeHeatmapChartTitle := drawChartTitle("RESPONSE TIME HEATMAP", responseTimeHeatmapChart, periodTableTitle)
+	tableChartsData = append(tableChartsData, []string{responseTimeHeatmapChartTitle})
+	tableChartsData = append(tableChartsData, []string{responseTimeHeatmapChart})
+
+	// Sub-table "timing phases"
+
+	timingsData, err := util.BinocsAPI("/checks/"+respJSON.Ident+"/timings?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return err
+	}
+	timings := make([]TimingsResponse, 0)
+	decoder = json.NewDecoder(bytes.NewBuffer(timingsData))
+	err = decoder.Decode(&timings)
+	if err != nil {
+		return err
+	}
+
+	timingsChart := drawTimingsChart(timings, tr.dataPoints(), "")
+	timingsChartTitle := drawChartTitle("TIMING PHASES", timingsChart, periodTableTitle)
+	tableChartsData = append(tableChartsData, []string{timingsChartTitle})
+	tableChartsData = append(tableChartsData, []string{timingsChart})
+
+	// Timeline
+
+	timeline := drawTimeline(&user, tr, "                ")
+	tableChartsData = append(tableChartsData, []string{timeline})
+
+	// Sub-table "response time histogram"
+
+	if checkInspectFlagHistogram {
+		histogramURLValues := url.Values{}
+		for k, v := range urlValues {
+			histogramURLValues[k] = v
+		}
+		histogramURLValues.Set("buckets", formatHistogramBuckets(checkInspectFlagHistogramBuckets, respJSON.Target))
+		histogramData, err := util.BinocsAPI("/checks/"+respJSON.Ident+"/response-time-histogram?"+histogramURLValues.Encode(), http.MethodGet, []byte{})
 		if err != nil {
-			handleErr(err)
+			return err
 		}
-		responseTimeHeatmap := make([]ResponseTimeHeatmapResponse, 0)
-		decoder = json.NewDecoder(bytes.NewBuffer(responseTimeHeatmapData))
-		err = decoder.Decode(&responseTimeHeatmap)
+		histogram := make([]ResponseTimeHistogramResponse, 0)
+		decoder = json.NewDecoder(bytes.NewBuffer(histogramData))
+		err = decoder.Decode(&histogram)
 		if err != nil {
-			handleErr(err)
+			return err
 		}
 
-		responseTimeHeatmapChart := drawResponseTimeHeatmapChart(responseTimeHeatmap, aggregateMetricsDataPoints[urlValues.Get("period")], respJSON.Target, "")
-		responseTimeHeatmapChartTitle := drawChartTitle("RESPONSE TIME HEATMAP", responseTimeHeatmapChart, periodTableTitle)
-		tableChartsData = append(tableChartsData, []string{responseTimeHeatmapChartTitle})
-		tableChartsData = append(tableChartsData, []string{responseTimeHeatmapChart})
-
-		// Timeline
-
-		timeline := drawTimeline(&user, urlValues.Get("period"), aggregateMetricsDataPoints[urlValues.Get("period")], "                ")
-		tableChartsData = append(tableChartsData, []string{timeline})
+		histogramChart := drawResponseTimeHistogramChart(histogram, respJSON.Target, tr.dataPoints())
+		histogramChartTitle := drawChartTitle("RESPONSE TIME HISTOGRAM", histogramChart, periodTableTitle)
+		tableChartsData = append(tableChartsData, []string{histogramChartTitle})
+		tableChartsData = append(tableChartsData, []string{histogramChart})
+	}
 
-		tableCharts := composeTable(tableChartsData, tableChartsColumnDefinitions)
-		tableCharts.SetRowLine(true)
+	tableCharts := composeTableTo(w, tableChartsData, tableChartsColumnDefinitions)
+	tableCharts.SetRowLine(true)
 
-		spin.Stop()
-		if user.CreditBalance == 0 {
-			printZeroCreditsWarning()
-		}
-		tableMain.Render()
-		tableCharts.Render()
-	},
+	spin.Stop()
+	if user.CreditBalance == 0 {
+		printZeroCreditsWarning(w)
+	}
+	tableMain.Render()
+	if tableRegions != nil {
+		tableRegions.Render()
+	}
+	tableCharts.Render()
+	return nil
 }
 
 var checkListCmd = &cobra.Command{
@@ -645,6 +929,9 @@ var checkListCmd = &cobra.Command{
 	Short: "List all checks with status and metrics overview",
 	Long: `
 List all checks with status and metrics overview.
+
+In --watch mode, cells whose status, HTTP code or metrics changed since the previous refresh are highlighted
+for one refresh, and status transitions are listed below the table.
 `,
 	Aliases:           []string{"ls"},
 	Args:              cobra.NoArgs,
@@ -652,167 +939,284 @@ List all checks with status and metrics overview.
 	Run: func(cmd *cobra.Command, args []string) {
 		util.VerifyAuthenticated()
 
+		if len(checkListFlagRegion) > 0 && !isValidRegionAlias(checkListFlagRegion) {
+			handleErr(fmt.Errorf("Invalid region provided. Supported regions: %s", strings.Join(getSupportedRegionAliases(), ", ")))
+		}
+
 		if checkListFlagWatch {
-			runAsWatch()
+			watch := &checkListWatch{bell: checkListFlagBell}
+			runAsWatch(func(w io.Writer) error {
+				return renderCheckList(w, watch)
+			}, time.Duration(checkListFlagInterval)*time.Second)
 			return
 		}
 
-		urlValues1 := url.Values{}
-		urlValues2 := url.Values{
-			"period": []string{"day"},
+		err := renderCheckList(os.Stdout, nil)
+		if err != nil {
+			handleErr(err)
 		}
-		apdexPeriodTableTitle := "1 DAY"
+	},
+}
 
-		match, err := regexp.MatchString(validPeriodPattern, checkListFlagPeriod)
-		if err == nil && match {
-			urlValues2.Set("period", checkListFlagPeriod)
-			switch checkListFlagPeriod {
-			case "hour":
-				apdexPeriodTableTitle = "1 HOUR"
-			case "day":
-				apdexPeriodTableTitle = "1 DAY"
-			case "week":
-				apdexPeriodTableTitle = "1 WEEK"
-			case "month":
-				apdexPeriodTableTitle = "1 MONTH"
-			}
-		}
+// renderCheckList writes the `check list` table to w; with watch, changes since the previous call are highlighted
+func renderCheckList(w io.Writer, watch *checkListWatch) error {
+	urlValues1 := url.Values{}
+	urlValues2 := url.Values{}
 
-		match = isValidRegionAlias(checkListFlagRegion)
-		if len(checkListFlagRegion) > 0 && !match {
-			handleErr(fmt.Errorf("Invalid region provided. Supported regions: " + strings.Join(getSupportedRegionAliases(), ", ")))
-		} else if err == nil && match {
-			urlValues2.Set("region", getRegionIdByAlias(checkListFlagRegion))
-		}
+	if isValidRegionAlias(checkListFlagRegion) {
+		urlValues2.Set("region", getRegionIdByAlias(checkListFlagRegion))
+	}
 
-		checkListFlagStatus = strings.ToUpper(checkListFlagStatus)
-		if checkListFlagStatus == statusNameUp || checkListFlagStatus == statusNameDown {
-			urlValues1.Set("status", checkListFlagStatus)
-		}
+	checkListFlagStatus = strings.ToUpper(checkListFlagStatus)
+	if checkListFlagStatus == statusNameUp || checkListFlagStatus == statusNameDown {
+		urlValues1.Set("status", checkListFlagStatus)
+	}
 
-		spin.Start()
-		defer spin.Stop()
-		spin.Suffix = colorFaint.Sprint(" loading checks...")
+	spin.Start()
+	defer spin.Stop()
+	spin.Suffix = colorFaint.Sprint(" loading checks...")
 
-		user, err := fetchUser()
-		if err != nil {
-			handleErr(err)
-		}
+	user, err := fetchUser()
+	if err != nil {
+		return err
+	}
 
-		checks, err := fetchChecks(urlValues1)
-		if err != nil {
-			handleErr(err)
+	tr, err := parseTimeRange(checkListFlagPeriod, checkListFlagFrom, checkListFlagTo, checkListFlagSince, periodDay, userLocation(&user))
+	if err != nil {
+		return err
+	}
+	tr.setURLValues(&urlValues2, true)
+	apdexPeriodTableTitle := tr.title()
+
+	checks, err := fetchChecks(urlValues1)
+	if err != nil {
+		return err
+	}
+	ch := make(chan tableRow)
+	var rows []tableRow
+	var checksLen int
+	for _, v := range checks {
+		if urlValues2.Has("region") && !util.StringInSlice(urlValues2.Get("region"), v.Regions) {
+			continue
 		}
-		ch := make(chan []string)
-		var tableData [][]string
-		var checksLen int
-		for _, v := range checks {
-			if urlValues2.Has("region") && !util.StringInSlice(urlValues2.Get("region"), v.Regions) {
-				continue
-			}
-			go makeCheckListRow(v, ch, &urlValues2, user.CreditBalance == 0)
-			checksLen++
+		go makeCheckListRow(v, ch, &urlValues2, user.CreditBalance == 0)
+		checksLen++
+	}
+	var i int
+	for _, v := range checks {
+		if urlValues2.Has("region") && !util.StringInSlice(urlValues2.Get("region"), v.Regions) {
+			continue
 		}
-		var i int
-		for _, v := range checks {
-			if urlValues2.Has("region") && !util.StringInSlice(urlValues2.Get("region"), v.Regions) {
-				continue
-			}
-			i++
-			spin.Suffix = colorFaint.Sprintf(" loading metrics... (%d/%d)", i, checksLen)
-			tableData = append(tableData, <-ch)
+		i++
+		spin.Suffix = colorFaint.Sprintf(" loading metrics... (%d/%d)", i, checksLen)
+		row := <-ch
+		if row.err != nil {
+			err = row.err
+			continue
 		}
-		sort.Slice(tableData, func(i, j int) bool {
-			return strings.ToLower(tableData[i][1]) < strings.ToLower(tableData[j][1])
-		})
-
-		columnDefinitions := []tableColumnDefinition{
-			{
-				Header:    "ID",
-				Priority:  1,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "NAME",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "URL/HOST",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "METHOD",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "STATUS",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "CHAN",
-				Priority:  4,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "HTTP",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-			{
-				Header:    "MRT",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-			{
-				Header:    "UPTIME",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-			{
-				Header:    "APDEX",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-			{
-				Header:    "APDEX " + apdexPeriodTableTitle,
-				Priority:  4,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
+		rows = append(rows, row)
+	}
+	if err != nil {
+		return err
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return strings.ToLower(rows[i].cells[1]) < strings.ToLower(rows[j].cells[1])
+	})
+	var tableData [][]string
+	for _, row := range rows {
+		tableData = append(tableData, row.cells)
+	}
+
+	columnDefinitions := []tableColumnDefinition{
+		{
+			Header:    "ID",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "NAME",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "URL/HOST",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "METHOD",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "STATUS",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "CHAN",
+			Priority:  4,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "HTTP",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "MRT",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "P50",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+			hidden:    !checkListFlagPercentiles,
+		},
+		{
+			Header:    "P90",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+			hidden:    !checkListFlagPercentiles,
+		},
+		{
+			Header:    "P95",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+			hidden:    !checkListFlagPercentiles,
+		},
+		{
+			Header:    "P99",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_RIGHT,
+			hidden:    !checkListFlagPercentiles,
+		},
+		{
+			Header:    "UPTIME",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "APDEX",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "APDEX " + apdexPeriodTableTitle,
+			Priority:  4,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+	}
+
+	decorateStatusColumn(tableData)
+	var down bool
+	if watch != nil {
+		down = watch.compare(rows, time.Now().In(userLocation(&user)))
+	}
+	table := composeTableTo(w, tableData, columnDefinitions)
+	spin.Stop()
+	if user.CreditBalance == 0 {
+		printZeroCreditsWarning(w)
+	}
+	table.Render()
+	if watch != nil {
+		watch.renderEvents(w)
+		if b, ok := w.(*watchBuffer); ok && down && watch.bell {
+			b.bell = true
 		}
+	}
+	return nil
+}
 
-		decorateStatusColumn(tableData)
-		table := composeTable(tableData, columnDefinitions)
-		spin.Stop()
-		if user.CreditBalance == 0 {
-			printZeroCreditsWarning()
+// tableRow is a table row composed in a goroutine, or the error that prevented composing it;
+// check list rows also carry the values that --watch compares between refreshes
+type tableRow struct {
+	cells    []string
+	snapshot checkListSnapshot
+	err      error
+}
+
+// checkListSnapshot holds raw values of a check list row, in order of checkListWatchColumns
+type checkListSnapshot struct {
+	ident  string
+	name   string
+	status int
+	values []string
+}
+
+// status, HTTP, MRT, P50, P90, P95, P99, UPTIME and APDEX columns of the check list table
+var checkListWatchColumns = []int{4, 6, 7, 8, 9, 10, 11, 12, 13}
+
+const checkListWatchMaxEvents = 10
+
+var (
+	colorHighlight   = color.New(color.BgYellow, color.FgBlack)
+	ansiEscapeRegexp = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// checkListWatch keeps the check list data set of the previous --watch refresh, and the status transitions seen so far
+type checkListWatch struct {
+	previous map[string]checkListSnapshot
+	events   []string
+	bell     bool
+}
+
+// compare highlights cells of rows that changed since the previous refresh, records status transitions,
+// and reports whether any check went down
+func (cw *checkListWatch) compare(rows []tableRow, now time.Time) bool {
+	var down bool
+	current := make(map[string]checkListSnapshot)
+	for _, row := range rows {
+		current[row.snapshot.ident] = row.snapshot
+		prev, ok := cw.previous[row.snapshot.ident]
+		if !ok {
+			continue
+		}
+		for i, column := range checkListWatchColumns {
+			if row.snapshot.values[i] != prev.values[i] {
+				row.cells[column] = colorHighlight.Sprint(ansiEscapeRegexp.ReplaceAllString(row.cells[column], ""))
+			}
 		}
-		table.Render()
-	},
+		if row.snapshot.status != prev.status {
+			name := row.snapshot.name
+			if name == "" {
+				name = row.snapshot.ident
+			}
+			event := fmt.Sprintf("%s %s → %s %s", name, statusName[prev.status], statusName[row.snapshot.status], now.Format("15:04:05"))
+			cw.events = append([]string{event}, cw.events...)
+			if row.snapshot.status == statusStepDown || row.snapshot.status == statusDown {
+				down = true
+			}
+		}
+	}
+	if len(cw.events) > checkListWatchMaxEvents {
+		cw.events = cw.events[:checkListWatchMaxEvents]
+	}
+	cw.previous = current
+	return down
+}
+
+func (cw *checkListWatch) renderEvents(w io.Writer) {
+	if len(cw.events) == 0 {
+		return
+	}
+	fmt.Fprintln(w, colorBold.Sprint("Recent changes"))
+	for _, e := range cw.events {
+		fmt.Fprintln(w, e)
+	}
 }
 
-func makeCheckListRow(check Check, ch chan<- []string, urlValues *url.Values, zeroCredits bool) {
+func makeCheckListRow(check Check, ch chan<- tableRow, urlValues *url.Values, zeroCredits bool) {
 	lastStatusCodeRegex, _ := regexp.Compile(`^[1-5]{1}[0-9]{2}`)
 	lastStatusCodeMatch := lastStatusCodeRegex.FindString(check.LastStatusCode)
 	if lastStatusCodeMatch == "" {
 		lastStatusCodeMatch = "-"
 	}
-	metrics, err := fetchMetrics(check.Ident, urlValues)
-	if err != nil {
-		handleErr(err)
-	}
-	apdexData, err := util.BinocsAPI("/checks/"+check.Ident+"/apdex?"+urlValues.Encode(), http.MethodGet, []byte{})
-	if err != nil {
-		handleErr(err)
-	}
-	apdex := make([]ApdexResponse, 0)
-	decoder := json.NewDecoder(bytes.NewBuffer(apdexData))
-	err = decoder.Decode(&apdex)
+	metrics, apdex, err := fetchCheckListData(check.Ident, urlValues)
 	if err != nil {
-		handleErr(err)
+		ch <- tableRow{err: err}
+		return
 	}
 	apdexChart := drawCompactApdexChart(apdex, metrics.Apdex)
 	tableValueMRT := formatMRT(metrics.MRT)
@@ -837,22 +1241,101 @@ func makeCheckListRow(check Check, ch chan<- []string, urlValues *url.Values, ze
 	} else {
 		name = colorBold.Sprint(check.Name)
 	}
-	var identSnippet, statusSnippet, lastStatusCodeSnippet string
-	identSnippet = colorBold.Sprint(check.Ident)
-	statusSnippet = formatStatus(&check)
-	lastStatusCodeSnippet = lastStatusCodeMatch
+	var identSnippet, statusSnippet, lastStatusCodeSnippet string
+	identSnippet = colorBold.Sprint(check.Ident)
+	statusSnippet = formatStatus(&check)
+	lastStatusCodeSnippet = lastStatusCodeMatch
+	if zeroCredits {
+		statusSnippet = color.YellowString(statusName[statusUnknown])
+		lastStatusCodeSnippet = "n/a"
+		tableValueMRT = "n/a"
+		tableValueUptime = "n/a"
+		tableValueApdex = "n/a"
+	}
+	tableValueP50 := formatPercentile(metrics.P50, check.Target)
+	tableValueP90 := formatPercentile(metrics.P90, check.Target)
+	tableValueP95 := formatPercentile(metrics.P95, check.Target)
+	tableValueP99 := formatPercentile(metrics.P99, check.Target)
+	if zeroCredits {
+		tableValueP50, tableValueP90, tableValueP95, tableValueP99 = "n/a", "n/a", "n/a", "n/a"
+	}
+	row := []string{
+		identSnippet, name, util.Ellipsis(check.Resource, 40), colorFaint.Sprint(method), statusSnippet,
+		colorFaint.Sprint(strconv.Itoa(len(check.Channels))), lastStatusCodeSnippet, tableValueMRT,
+		tableValueP50, tableValueP90, tableValueP95, tableValueP99,
+		tableValueUptime, tableValueApdex, apdexChart,
+	}
+	snapshot := checkListSnapshot{
+		ident:  check.Ident,
+		name:   check.Name,
+		status: check.LastStatus,
+		values: []string{
+			strconv.Itoa(check.LastStatus), lastStatusCodeMatch, metrics.MRT,
+			metrics.P50, metrics.P90, metrics.P95, metrics.P99, metrics.Uptime, metrics.Apdex,
+		},
+	}
+	ch <- tableRow{cells: row, snapshot: snapshot}
+}
+
+// fetchCheckListData loads metrics and the Apdex chart data of a check list row
+func fetchCheckListData(ident string, urlValues *url.Values) (MetricsResponse, []ApdexResponse, error) {
+	metrics, err := fetchMetrics(ident, urlValues)
+	if err != nil {
+		return metrics, nil, err
+	}
+	apdex, err := fetchApdex(ident, urlValues)
+	if err != nil {
+		return metrics, nil, err
+	}
+	return metrics, apdex, nil
+}
+
+func makeRegionBreakdownRow(check Check, region string, urlValues url.Values, withApdexChart bool, zeroCredits bool, ch chan<- tableRow) {
+	regionURLValues := url.Values{}
+	for k, v := range urlValues {
+		regionURLValues[k] = v
+	}
+	regionURLValues.Set("region", region)
+	metrics, err := fetchMetrics(check.Ident, &regionURLValues)
+	if err != nil {
+		ch <- tableRow{err: err}
+		return
+	}
+	lastStatusCodeRegex, _ := regexp.Compile(`^[1-5]{1}[0-9]{2}`)
+	lastStatusCodeSnippet := lastStatusCodeRegex.FindString(metrics.LastStatusCode)
+	if lastStatusCodeSnippet == "" {
+		lastStatusCodeSnippet = "-"
+	}
+	tableValueMRT := formatMRT(metrics.MRT)
+	tableValueUptime := formatUptime(metrics.Uptime)
+	tableValueApdex := formatApdex(metrics.Apdex)
 	if zeroCredits {
-		statusSnippet = color.YellowString(statusName[statusUnknown])
 		lastStatusCodeSnippet = "n/a"
 		tableValueMRT = "n/a"
 		tableValueUptime = "n/a"
 		tableValueApdex = "n/a"
 	}
-	tableRow := []string{
-		identSnippet, name, util.Ellipsis(check.Resource, 40), colorFaint.Sprint(method), statusSnippet,
-		colorFaint.Sprint(strconv.Itoa(len(check.Channels))), lastStatusCodeSnippet, tableValueMRT, tableValueUptime, tableValueApdex, apdexChart,
+	row := []string{regionAliases[region], lastStatusCodeSnippet, tableValueMRT, tableValueUptime, tableValueApdex}
+	if withApdexChart {
+		var apdexChart string
+		if metrics.Apdex != "" {
+			apdexData, err := util.BinocsAPI("/checks/"+check.Ident+"/apdex?"+regionURLValues.Encode(), http.MethodGet, []byte{})
+			if err != nil {
+				ch <- tableRow{err: err}
+				return
+			}
+			apdex := make([]ApdexResponse, 0)
+			decoder := json.NewDecoder(bytes.NewBuffer(apdexData))
+			err = decoder.Decode(&apdex)
+			if err != nil {
+				ch <- tableRow{err: err}
+				return
+			}
+			apdexChart = drawCompactApdexChart(apdex, metrics.Apdex)
+		}
+		row = append(row, apdexChart)
 	}
-	ch <- tableRow
+	ch <- tableRow{cells: row}
 }
 
 func decorateStatusColumn(tableData [][]string) {
@@ -939,6 +1422,75 @@ This command is interactive and asks user for confirmation.
 	},
 }
 
+var checkTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run a check once from this machine",
+	Long: `
+Run a check once from this machine and evaluate the result.
+
+Test an existing check by providing its identifier, or a check you are about to add by providing its attributes as flags.
+`,
+	Aliases:           []string{"try"},
+	Args:              cobra.MaximumNArgs(1),
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		var check Check
+		if len(args) == 1 {
+			util.VerifyAuthenticated()
+			spin.Start()
+			defer spin.Stop()
+			spin.Suffix = colorFaint.Sprint(" loading check...")
+			respData, err := util.BinocsAPI("/checks/"+args[0], http.MethodGet, []byte{})
+			if err != nil {
+				handleErr(err)
+			}
+			err = json.Unmarshal(respData, &check)
+			if err != nil {
+				handleErr(err)
+			}
+			spin.Stop()
+		} else {
+			check = Check{
+				Protocol: strings.ToUpper(checkTestFlagProtocol),
+				Resource: checkTestFlagResource,
+				Method:   strings.ToUpper(checkTestFlagMethod),
+				Target:   checkTestFlagTarget,
+				UpCodes:  checkTestFlagUpCodes,
+			}
+			err := validateCheckTestInput(&check)
+			if err != nil {
+				handleErr(err)
+			}
+		}
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprintf(" checking %s...", check.Resource)
+
+		var result util.ProbeResult
+		if check.Protocol == protocolTCP {
+			result = util.ProbeTCP(check.Resource)
+		} else {
+			result = util.ProbeHTTP(check.Method, check.Resource)
+		}
+		request := makeProbeRequest(&check, &result)
+
+		tableRow := makeRequestTableRow(request)
+		tableRow[1] = getUserHostname()
+		tableRow = append(tableRow, formatProbeVerdict(&check, &result))
+		tableColumnDefinitions := requestsTableColumnDefinitions()
+		tableColumnDefinitions = append(tableColumnDefinitions, tableColumnDefinition{
+			Header:    "VERDICT",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		})
+		table := composeTable([][]string{tableRow}, tableColumnDefinitions)
+
+		spin.Stop()
+		table.Render()
+	},
+}
+
 func fetchChecks(urlValues url.Values) ([]Check, error) {
 	var checks []Check
 	respData, err := util.BinocsAPI("/checks?"+urlValues.Encode(), http.MethodGet, []byte{})
@@ -970,6 +1522,39 @@ func fetchMetrics(ident string, urlValues *url.Values) (MetricsResponse, error)
 	return metrics, nil
 }
 
+func fetchApdex(ident string, urlValues *url.Values) ([]ApdexResponse, error) {
+	apdex := make([]ApdexResponse, 0)
+	apdexData, err := util.BinocsAPI("/checks/"+ident+"/apdex?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return apdex, err
+	}
+	decoder := json.NewDecoder(bytes.NewBuffer(apdexData))
+	err = decoder.Decode(&apdex)
+	return apdex, err
+}
+
+func fetchResponseCodes(ident string, urlValues *url.Values) ([]ResponseCodesResponse, error) {
+	responseCodes := make([]ResponseCodesResponse, 0)
+	responseCodesData, err := util.BinocsAPI("/checks/"+ident+"/response-codes?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return responseCodes, err
+	}
+	decoder := json.NewDecoder(bytes.NewBuffer(responseCodesData))
+	err = decoder.Decode(&responseCodes)
+	return responseCodes, err
+}
+
+func fetchResponseTimeHeatmap(ident string, urlValues *url.Values) ([]ResponseTimeHeatmapResponse, error) {
+	responseTimeHeatmap := make([]ResponseTimeHeatmapResponse, 0)
+	responseTimeHeatmapData, err := util.BinocsAPI("/checks/"+ident+"/response-time-heatmap?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return responseTimeHeatmap, err
+	}
+	decoder := json.NewDecoder(bytes.NewBuffer(responseTimeHeatmapData))
+	err = decoder.Decode(&responseTimeHeatmap)
+	return responseTimeHeatmap, err
+}
+
 func formatStatus(c *Check) string {
 	var snippet string
 	switch c.LastStatus {
@@ -987,6 +1572,122 @@ func formatStatus(c *Check) string {
 	return snippet
 }
 
+func validateCheckTestInput(check *Check) error {
+	if check.Resource == "" {
+		return fmt.Errorf("Provide a check identifier, or at least the --resource flag")
+	}
+	if check.Protocol == "" {
+		switch {
+		case strings.HasPrefix(check.Resource, "http://"):
+			check.Protocol = protocolHTTP
+		case strings.HasPrefix(check.Resource, "tcp://"):
+			check.Protocol = protocolTCP
+		default:
+			check.Protocol = protocolHTTPS
+		}
+	}
+	match, err := regexp.MatchString(validProtocolPattern, check.Protocol)
+	if err != nil {
+		return err
+	} else if !match {
+		return fmt.Errorf("Invalid protocol, use HTTP, HTTPS or TCP")
+	}
+	switch check.Protocol {
+	case protocolHTTP:
+		if !isValidHTTPResource(check.Resource) {
+			return fmt.Errorf("Invalid HTTP URL")
+		}
+	case protocolHTTPS:
+		if !isValidHTTPSResource(check.Resource) {
+			return fmt.Errorf("Invalid HTTPS URL")
+		}
+	case protocolTCP:
+		if !isValidTCPResource(check.Resource) {
+			return fmt.Errorf("Invalid TCP <host>:<port>")
+		}
+	}
+	check.Resource = setProtocolPrefix(check.Resource, check.Protocol)
+	if check.Protocol == protocolTCP {
+		check.Method = ""
+		check.UpCodes = ""
+	} else {
+		match, err = regexp.MatchString(validMethodPattern, check.Method)
+		if err != nil {
+			return err
+		} else if !match {
+			return fmt.Errorf("Invalid HTTP method, use GET, HEAD, POST, PUT or DELETE")
+		}
+		match, err = regexp.MatchString(validUpCodePattern, check.UpCodes)
+		if err != nil {
+			return err
+		} else if !match {
+			return fmt.Errorf("Invalid up_codes value, use e.g. 2xx or 200-302, or 200,301")
+		}
+	}
+	if check.Target < supportedTargetMinimum || check.Target > supportedTargetMaximum {
+		return fmt.Errorf("Target Response Time must be a value between %.3f and %.3f", supportedTargetMinimum, supportedTargetMaximum)
+	}
+	return nil
+}
+
+func makeProbeRequest(check *Check, result *util.ProbeResult) Request {
+	request := Request{
+		RequestProtocol:    check.Protocol,
+		RequestResource:    check.Resource,
+		RequestMethod:      check.Method,
+		ResponseStatusCode: result.Status,
+		Timestamp:          result.Timestamp.Format("2006-01-02 15:04:05 -0700"),
+	}
+	if result.Err != nil {
+		request.ResponseStatusCode = util.Ellipsis(result.Err.Error(), 60)
+		request.Timings = Timings{DSNLookup: "nil"}
+		return request
+	}
+	request.Timings = Timings{
+		DSNLookup:  fmt.Sprintf("%.3f", result.Timings.DNSLookup.Seconds()),
+		Connection: fmt.Sprintf("%.3f", result.Timings.Connection.Seconds()),
+		TLS:        fmt.Sprintf("%.3f", result.Timings.TLS.Seconds()),
+		Wait:       fmt.Sprintf("%.3f", result.Timings.Wait.Seconds()),
+		Transfer:   fmt.Sprintf("%.3f", result.Timings.Transfer.Seconds()),
+	}
+	return request
+}
+
+func formatProbeVerdict(check *Check, result *util.ProbeResult) string {
+	if result.Err != nil {
+		return color.RedString(statusNameDown)
+	}
+	if check.Protocol == protocolHTTP || check.Protocol == protocolHTTPS {
+		if !isUpStatusCode(result.StatusCode, check.UpCodes) {
+			return color.RedString(statusNameDown) + colorFaint.Sprintf(" %d not in %s", result.StatusCode, check.UpCodes)
+		}
+	}
+	if result.Timings.Total().Seconds() > check.Target {
+		return color.GreenString(statusNameUp) + colorFaint.Sprintf(" slower than target %.3f s", check.Target)
+	}
+	return color.GreenString(statusNameUp)
+}
+
+func isUpStatusCode(code int, upCodes string) bool {
+	for _, r := range strings.Split(upCodes, ",") {
+		if ok, _ := util.IsCodeInRange(code, r); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func formatDownRegionsThreshold(c *Check) string {
+	threshold := c.DownRegionsThreshold
+	if threshold < 1 {
+		threshold = 1
+	}
+	if threshold == 1 {
+		return fmt.Sprintf("DOWN in any of %d regions", len(c.Regions))
+	}
+	return fmt.Sprintf("DOWN in %d of %d regions", threshold, len(c.Regions))
+}
+
 func formatMRT(mrt string) string {
 	if mrt == "" || mrt == "nil" {
 		return colorFaint.Sprint("n/a")
@@ -994,6 +1695,48 @@ func formatMRT(mrt string) string {
 	return mrt + " s"
 }
 
+// formatMetricDelta renders the change of a metric against the compared period with an arrow;
+// improvements are green and deteriorations red, depending on whether higher values are better
+func formatMetricDelta(current, previous string, precision int, unit string, higherIsBetter bool) string {
+	currentFloat, err := strconv.ParseFloat(current, 64)
+	if err != nil {
+		return colorFaint.Sprint("(n/a)")
+	}
+	previousFloat, err := strconv.ParseFloat(previous, 64)
+	if err != nil {
+		return colorFaint.Sprint("(n/a)")
+	}
+	delta := currentFloat - previousFloat
+	snippet := strconv.FormatFloat(math.Abs(delta), 'f', precision, 64) + unit
+	if snippet == strconv.FormatFloat(0, 'f', precision, 64)+unit {
+		return colorFaint.Sprint("= " + snippet)
+	}
+	arrow := "▲ "
+	if delta < 0 {
+		arrow = "▼ "
+	}
+	if (delta > 0) == higherIsBetter {
+		return color.GreenString(arrow + snippet)
+	}
+	return color.RedString(arrow + snippet)
+}
+
+// formatPercentile colours a response time percentile the way Apdex classifies requests:
+// satisfied up to Target, tolerating up to 4 × Target and frustrated above
+func formatPercentile(p string, target float64) string {
+	pFloat, err := strconv.ParseFloat(p, 64)
+	if p == "" || p == "nil" || err != nil {
+		return colorFaint.Sprint("n/a")
+	}
+	if pFloat <= target {
+		return color.GreenString("%v s", p)
+	}
+	if pFloat <= 4*target {
+		return color.YellowString("%v s", p)
+	}
+	return color.RedString("%v s", p)
+}
+
 func formatUptime(uptime string) string {
 	var empty = "n/a"
 	var uptimeFloat, err = strconv.ParseFloat(uptime, 32)
@@ -1003,13 +1746,18 @@ func formatUptime(uptime string) string {
 	if err != nil {
 		return color.HiBlackString(empty)
 	}
-	if uptimeFloat == 100.0 {
-		return color.GreenString("%v %%", uptime)
+	return uptimeColor(uptimeFloat).Sprintf("%v %%", uptime)
+}
+
+// uptimeColor is green for full uptime, yellow above 99.9 % and red otherwise
+func uptimeColor(uptime float64) *color.Color {
+	if uptime == 100.0 {
+		return color.New(color.FgGreen)
 	}
-	if uptimeFloat > 99.9 {
-		return color.YellowString("%v %%", uptime)
+	if uptime > 99.9 {
+		return color.New(color.FgYellow)
 	}
-	return color.RedString("%v %%", uptime)
+	return color.New(color.FgRed)
 }
 
 func formatApdex(apdex string) string {
@@ -1105,10 +1853,34 @@ func getApdexChartRowRange(i, numRows int) string {
 	return fmt.Sprintf("%.1f - %.1f", down, up)
 }
 
-func drawApdexChart(apdex []ApdexResponse, dataPoints int, leftMargin string) string {
-	const numRows = 5
-	var rows [numRows]string
+// drawApdexChart draws Apdex values; if compareApdex is not nil, values of the compared period are shown in faint colour
+func drawApdexChart(apdex []ApdexResponse, compareApdex []ApdexResponse, dataPoints int, leftMargin string) string {
+	const numRows = apdexChartNumRows
 	var chart string
+	rows := apdexChartRows(apdex, dataPoints)
+	var compareRows [numRows]string
+	if compareApdex != nil {
+		compareRows = apdexChartRows(compareApdex, dataPoints)
+	}
+	for i := 0; i < numRows; i++ {
+		rowFraction := (float64(i) + 1.0) / float64(numRows)
+		if rowFraction > 0.8 {
+			chart = leftMargin + getApdexChartRowRange(i, numRows) + " " + overlayChartRow(rows[i], compareRows[i], color.New(color.FgGreen)) + "\n" + chart
+		} else if rowFraction > 0.6 {
+			chart = leftMargin + getApdexChartRowRange(i, numRows) + " " + overlayChartRow(rows[i], compareRows[i], color.New(color.FgYellow)) + "\n" + chart
+		} else {
+			chart = leftMargin + getApdexChartRowRange(i, numRows) + " " + overlayChartRow(rows[i], compareRows[i], color.New(color.FgRed)) + "\n" + chart
+		}
+	}
+	chart = strings.TrimSuffix(chart, "\n")
+	return chart
+}
+
+const apdexChartNumRows = 5
+
+func apdexChartRows(apdex []ApdexResponse, dataPoints int) [apdexChartNumRows]string {
+	const numRows = apdexChartNumRows
+	var rows [numRows]string
 	for _, v := range apdex {
 		var vf, _ = strconv.ParseFloat(v.Apdex, 32)
 		for i := 0; i < numRows; i++ {
@@ -1128,24 +1900,39 @@ func drawApdexChart(apdex []ApdexResponse, dataPoints int, leftMargin string) st
 			rows[i] = strings.Repeat(" ", dataPoints-len(apdex)) + rows[i]
 		}
 	}
+	return rows
+}
+
+// drawResponseCodesChart draws response code classes; if compareResponseCodes is not nil, codes of the compared period are shown in faint colour
+func drawResponseCodesChart(responseCodes []ResponseCodesResponse, compareResponseCodes []ResponseCodesResponse, dataPoints int, upCodes string, yAreaWidth int) string {
+	const numRows = responseCodesChartNumRows
+	var chart string
+	rows := responseCodesChartRows(responseCodes, dataPoints)
+	var compareRows [numRows]string
+	if compareResponseCodes != nil {
+		compareRows = responseCodesChartRows(compareResponseCodes, dataPoints)
+	}
 	for i := 0; i < numRows; i++ {
-		rowFraction := (float64(i) + 1.0) / float64(numRows)
-		if rowFraction > 0.8 {
-			chart = leftMargin + getApdexChartRowRange(i, numRows) + " " + color.GreenString(rows[i]) + "\n" + chart
-		} else if rowFraction > 0.6 {
-			chart = leftMargin + getApdexChartRowRange(i, numRows) + " " + color.YellowString(rows[i]) + "\n" + chart
+		if i == numRows-1 { // the err case
+			chart = chart + strings.Repeat(" ", yAreaWidth-6) + "error" + " " + overlayChartRow(rows[i], compareRows[i], color.New(color.FgRed)) + "\n"
 		} else {
-			chart = leftMargin + getApdexChartRowRange(i, numRows) + " " + color.RedString(rows[i]) + "\n" + chart
+			// @todo we should distinguish between a green code (in range and up), and a yellow code (in range and down, e.g. 303 in default range setup)
+			if ok, _ := util.IsCodeInRange((i+1)*100, upCodes); ok {
+				chart = chart + strings.Repeat(" ", yAreaWidth-4) + strconv.Itoa(i+1) + "xx" + " " + overlayChartRow(rows[i], compareRows[i], color.New(color.FgGreen)) + "\n"
+			} else {
+				chart = chart + strings.Repeat(" ", yAreaWidth-4) + strconv.Itoa(i+1) + "xx" + " " + overlayChartRow(rows[i], compareRows[i], color.New(color.FgRed)) + "\n"
+			}
 		}
 	}
 	chart = strings.TrimSuffix(chart, "\n")
 	return chart
 }
 
-func drawResponseCodesChart(responseCodes []ResponseCodesResponse, dataPoints int, upCodes string, yAreaWidth int) string {
-	const numRows = 6
+const responseCodesChartNumRows = 6
+
+func responseCodesChartRows(responseCodes []ResponseCodesResponse, dataPoints int) [responseCodesChartNumRows]string {
+	const numRows = responseCodesChartNumRows
 	var rows [numRows]string
-	var chart string
 	for _, v := range responseCodes {
 		if v.Xx1 > 0 {
 			rows[0] = rows[0] + "▩"
@@ -1183,20 +1970,7 @@ func drawResponseCodesChart(responseCodes []ResponseCodesResponse, dataPoints in
 			rows[i] = strings.Repeat(" ", dataPoints-len(responseCodes)) + rows[i]
 		}
 	}
-	for i := 0; i < numRows; i++ {
-		if i == numRows-1 { // the err case
-			chart = chart + strings.Repeat(" ", yAreaWidth-6) + "error" + " " + color.RedString(rows[i]) + "\n"
-		} else {
-			// @todo we should distinguish between a green code (in range and up), and a yellow code (in range and down, e.g. 303 in default range setup)
-			if ok, _ := util.IsCodeInRange((i+1)*100, upCodes); ok {
-				chart = chart + strings.Repeat(" ", yAreaWidth-4) + strconv.Itoa(i+1) + "xx" + " " + color.GreenString(rows[i]) + "\n"
-			} else {
-				chart = chart + strings.Repeat(" ", yAreaWidth-4) + strconv.Itoa(i+1) + "xx" + " " + color.RedString(rows[i]) + "\n"
-			}
-		}
-	}
-	chart = strings.TrimSuffix(chart, "\n")
-	return chart
+	return rows
 }
 
 func drawResponseTimeHeatmapChart(responseTimeHeatmap []ResponseTimeHeatmapResponse, dataPoints int, targetTime float64, leftMargin string) string {
@@ -1294,6 +2068,318 @@ func drawResponseTimeHeatmapChart(responseTimeHeatmap []ResponseTimeHeatmapRespo
 	return chart
 }
 
+// overlayChartRow colours the cells of row with c; cells empty in row but set in compareRow are shown in faint colour
+func overlayChartRow(row string, compareRow string, c *color.Color) string {
+	if compareRow == "" {
+		return c.Sprint(row)
+	}
+	current := []rune(row)
+	compared := []rune(compareRow)
+	var out, run string
+	var runFaint bool
+	for i, r := range current {
+		faint := r == ' ' && i < len(compared) && compared[i] != ' '
+		if faint {
+			r = compared[i]
+		}
+		if faint != runFaint && len(run) > 0 {
+			out = out + overlayChartRun(run, runFaint, c)
+			run = ""
+		}
+		run = run + string(r)
+		runFaint = faint
+	}
+	return out + overlayChartRun(run, runFaint, c)
+}
+
+func overlayChartRun(run string, faint bool, c *color.Color) string {
+	if faint {
+		return colorFaint.Sprint(run)
+	}
+	return c.Sprint(run)
+}
+
+// brailleDots are bits of braille pattern dots, indexed by column and by row from the top
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// drawResponseTimeChart draws MRT as a braille line, one character per data point with four levels per row;
+// min/max bands are drawn in faint colour and Target is marked with a dashed line
+func drawResponseTimeChart(responseTime []ResponseTimeResponse, dataPoints int, target float64, withBands bool, leftMargin string) string {
+	const numRows = 6
+	const numLevels = numRows * 4
+	var chart string
+
+	maximum := target * 1.1
+	for _, v := range responseTime {
+		value := v.MRT
+		if withBands {
+			value = v.Max
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil && f > maximum {
+			maximum = f
+		}
+	}
+	level := func(value float64) int {
+		l := int(math.Round(value / maximum * (numLevels - 1)))
+		if l > numLevels-1 {
+			l = numLevels - 1
+		}
+		return l
+	}
+	targetLevel := level(target)
+
+	offset := 0
+	if len(responseTime) < dataPoints {
+		offset = dataPoints - len(responseTime)
+	}
+	width := offset + len(responseTime)
+	lineDots := make([][numRows]rune, width)
+	bandDots := make([][numRows]rune, width)
+	lineColors := make([]*color.Color, width)
+	setDot := func(dots [][numRows]rune, x, col, l int) {
+		row := numRows - 1 - l/4
+		dots[x][row] |= brailleDots[col][3-l%4]
+	}
+
+	previousLevel := -1
+	for i, v := range responseTime {
+		x := offset + i
+		mrt, err := strconv.ParseFloat(v.MRT, 64)
+		if err != nil {
+			previousLevel = -1
+			continue
+		}
+		if withBands {
+			minValue, errMin := strconv.ParseFloat(v.Min, 64)
+			maxValue, errMax := strconv.ParseFloat(v.Max, 64)
+			if errMin == nil && errMax == nil {
+				for l := level(minValue); l <= level(maxValue); l++ {
+					setDot(bandDots, x, 0, l)
+					setDot(bandDots, x, 1, l)
+				}
+			}
+		}
+		l := level(mrt)
+		from, to := l, l
+		if previousLevel >= 0 {
+			from, to = previousLevel, l
+			if from > to {
+				from, to = to, from
+			}
+		}
+		for ll := from; ll <= to; ll++ {
+			setDot(lineDots, x, 0, ll)
+		}
+		setDot(lineDots, x, 1, l)
+		previousLevel = l
+		switch {
+		case mrt <= target:
+			lineColors[x] = color.New(color.FgGreen)
+		case mrt <= 4*target:
+			lineColors[x] = color.New(color.FgYellow)
+		default:
+			lineColors[x] = color.New(color.FgRed)
+		}
+	}
+
+	for row := 0; row < numRows; row++ {
+		topLevel := (numRows-row)*4 - 1
+		rowTitle := fmt.Sprintf("%12.0f ms", float64(topLevel)/(numLevels-1)*maximum*1000)
+		if targetLevel/4 == numRows-1-row {
+			rowTitle = fmt.Sprintf("T %10.0f ms", target*1000)
+		}
+		var rowContent string
+		for x := 0; x < width; x++ {
+			switch {
+			case lineDots[x][row] != 0:
+				rowContent = rowContent + lineColors[x].Sprint(string(0x2800+(lineDots[x][row]|bandDots[x][row])))
+			case bandDots[x][row] != 0:
+				rowContent = rowContent + colorFaint.Sprint(string(0x2800+bandDots[x][row]))
+			case targetLevel/4 == numRows-1-row:
+				rowContent = rowContent + colorFaint.Sprint(string(0x2800+brailleDots[0][3-targetLevel%4]))
+			default:
+				rowContent = rowContent + " "
+			}
+		}
+		chart = chart + leftMargin + rowTitle + " " + rowContent + "\n"
+	}
+	chart = strings.TrimSuffix(chart, "\n")
+	return chart
+}
+
+// timingPhases are stacked from the bottom of the timing phases chart in the order a request goes through them
+var timingPhases = []struct {
+	title string
+	color *color.Color
+	value func(t Timings) string
+}{
+	{"DNS", color.New(color.FgBlue), func(t Timings) string { return t.DSNLookup }},
+	{"connection", color.New(color.FgCyan), func(t Timings) string { return t.Connection }},
+	{"TLS", color.New(color.FgMagenta), func(t Timings) string { return t.TLS }},
+	{"wait", color.New(color.FgYellow), func(t Timings) string { return t.Wait }},
+	{"transfer", color.New(color.FgGreen), func(t Timings) string { return t.Transfer }},
+}
+
+// drawTimingsChart draws average request phase durations as stacked columns, auto-scaled to the slowest data point
+func drawTimingsChart(timings []TimingsResponse, dataPoints int, leftMargin string) string {
+	const numRows = 8
+	var chart string
+	var maximum float64
+	phases := make([][]float64, len(timings))
+	for i, v := range timings {
+		var total float64
+		for _, p := range timingPhases {
+			d, _ := strconv.ParseFloat(p.value(v.Timings), 64)
+			phases[i] = append(phases[i], d)
+			total = total + d
+		}
+		if total > maximum {
+			maximum = total
+		}
+	}
+	if maximum == 0 {
+		return leftMargin + colorFaint.Sprint("[waiting for data]")
+	}
+	rowHeight := maximum / numRows
+	for row := numRows - 1; row >= 0; row-- {
+		// each cell shows the phase at the middle of its row
+		level := (float64(row) + 0.5) * rowHeight
+		cells := make([]int, 0, dataPoints)
+		for i := len(timings); i < dataPoints; i++ {
+			cells = append(cells, -1)
+		}
+		for _, phaseDurations := range phases {
+			cell := -1
+			var cumulative float64
+			for p, d := range phaseDurations {
+				cumulative = cumulative + d
+				if level < cumulative {
+					cell = p
+					break
+				}
+			}
+			cells = append(cells, cell)
+		}
+		chart = chart + leftMargin + fmt.Sprintf("%13.3f s", float64(row+1)*rowHeight) + " " + drawTimingsChartRow(cells) + "\n"
+	}
+	var legend []string
+	for _, p := range timingPhases {
+		legend = append(legend, p.color.Sprint("▩")+" "+p.title)
+	}
+	chart = chart + leftMargin + strings.Repeat(" ", 16) + strings.Join(legend, "  ")
+	return chart
+}
+
+func drawTimingsChartRow(cells []int) string {
+	var row, run string
+	runPhase := -1
+	flush := func() {
+		if runPhase < 0 {
+			row = row + run
+		} else {
+			row = row + timingPhases[runPhase].color.Sprint(run)
+		}
+		run = ""
+	}
+	for _, c := range cells {
+		if c != runPhase && len(run) > 0 {
+			flush()
+		}
+		runPhase = c
+		if c < 0 {
+			run = run + " "
+		} else {
+			run = run + "▩"
+		}
+	}
+	flush()
+	return row
+}
+
+func validateHistogramBuckets(buckets []float64) error {
+	if len(buckets) == 0 || len(buckets) > maxHistogramBuckets {
+		return fmt.Errorf("Provide between 1 and %d histogram buckets", maxHistogramBuckets)
+	}
+	for i, b := range buckets {
+		if b <= 0 || (i > 0 && b <= buckets[i-1]) {
+			return fmt.Errorf("Histogram buckets must be positive and in ascending order")
+		}
+	}
+	return nil
+}
+
+// formatHistogramBuckets converts bucket multiples of target to a list of boundaries in seconds
+func formatHistogramBuckets(buckets []float64, target float64) string {
+	var boundaries []string
+	for _, b := range buckets {
+		boundaries = append(boundaries, strconv.FormatFloat(b*target, 'f', 3, 64))
+	}
+	return strings.Join(boundaries, ",")
+}
+
+func drawResponseTimeHistogramChart(histogram []ResponseTimeHistogramResponse, target float64, width int) string {
+	var chart string
+	var total, maximum int
+	var rowTitles []string
+	var rowTitleWidth int
+	for i, v := range histogram {
+		if v.Count > maximum {
+			maximum = v.Count
+		}
+		total = total + v.Count
+		var rowTitle string
+		if v.Le == "+Inf" {
+			if i > 0 {
+				rowTitle = "> " + histogram[i-1].Le + " s"
+			} else {
+				rowTitle = "all"
+			}
+		} else {
+			rowTitle = "≤ " + v.Le + " s"
+		}
+		if le, err := strconv.ParseFloat(v.Le, 64); err == nil && !math.IsInf(le, 1) && target > 0 {
+			rowTitle = rowTitle + " " + colorFaint.Sprintf("(%s T)", strconv.FormatFloat(le/target, 'f', -1, 64))
+		}
+		rowTitles = append(rowTitles, rowTitle)
+		if ansi.PrintableRuneWidth(rowTitle) > rowTitleWidth {
+			rowTitleWidth = ansi.PrintableRuneWidth(rowTitle)
+		}
+	}
+	for i, v := range histogram {
+		var barWidth int
+		if maximum > 0 {
+			barWidth = int(math.Round(float64(v.Count) / float64(maximum) * float64(width)))
+		}
+		var share float64
+		if total > 0 {
+			share = 100 * float64(v.Count) / float64(total)
+		}
+		bar := strings.Repeat("▩", barWidth) + strings.Repeat(" ", width-barWidth)
+		upperBound, err := strconv.ParseFloat(v.Le, 64)
+		if err != nil {
+			upperBound = math.Inf(1)
+		}
+		switch {
+		case upperBound <= target:
+			bar = color.GreenString(bar)
+		case upperBound <= 4*target:
+			bar = color.YellowString(bar)
+		default:
+			bar = color.RedString(bar)
+		}
+		spacer := strings.Repeat(" ", rowTitleWidth-ansi.PrintableRuneWidth(rowTitles[i]))
+		chart = chart + spacer + rowTitles[i] + " " + bar + " " + fmt.Sprintf("%5.1f %%", share) + "\n"
+	}
+	if len(histogram) == 0 {
+		chart = colorFaint.Sprint("[waiting for data]")
+	}
+	chart = strings.TrimSuffix(chart, "\n")
+	return chart
+}
+
 func drawChartTitle(title string, chart string, periodTitle string) string {
 	chartRows := strings.Split(chart, "\n")
 	chartWidth := ansi.PrintableRuneWidth(chartRows[0])
@@ -1304,16 +2390,14 @@ func drawChartTitle(title string, chart string, periodTitle string) string {
 	return colorBold.Sprint(title)
 }
 
-func drawTimeline(user *User, period string, dataPoints int, leftMargin string) string {
+func drawTimeline(user *User, tr timeRange, leftMargin string) string {
 	var timeline [2]string
 
-	tz, err := time.LoadLocation(user.Timezone)
-	if err != nil {
-		tz = time.UTC
-	}
+	tz := userLocation(user)
+	dataPoints := tr.dataPoints()
 
 	var now = time.Now().In(tz)
-	switch period {
+	switch tr.Period {
 	case periodHour:
 		for i := 0; i < 15; i++ {
 			if i == 0 {
@@ -1365,6 +2449,8 @@ func drawTimeline(user *User, period string, dataPoints int, leftMargin string)
 				timeline[1] = now.Format("Jan") + strings.Repeat(" ", gap) + timeline[1]
 			}
 		}
+	default:
+		timeline = drawRangeTimeline(tr, tz)
 	}
 	if len(timeline[0]) < dataPoints {
 		timeline[0] = strings.Repeat(" ", dataPoints-len(timeline[0])) + timeline[0]
@@ -1389,7 +2475,7 @@ func setProtocolPrefix(res, proto string) string {
 
 func checkAddOrUpdate(mode string, checkIdent string) {
 	if mode != "add" && mode != "update" {
-		handleErr(fmt.Errorf("Unknown mode: " + mode))
+		handleErr(fmt.Errorf("Unknown mode: %s", mode))
 	}
 
 	var err error
@@ -1407,6 +2493,7 @@ func checkAddOrUpdate(mode string, checkIdent string) {
 		flagUpCodes                    string
 		flagUpConfirmationsThreshold   int
 		flagDownConfirmationsThreshold int
+		flagDownRegionsThreshold       int
 		flagAttach                     []string
 	)
 
@@ -1422,6 +2509,7 @@ func checkAddOrUpdate(mode string, checkIdent string) {
 		flagUpCodes = checkAddFlagUpCodes
 		flagUpConfirmationsThreshold = checkAddFlagUpConfirmationsThreshold
 		flagDownConfirmationsThreshold = checkAddFlagDownConfirmationsThreshold
+		flagDownRegionsThreshold = checkAddFlagDownRegionsThreshold
 		flagAttach = checkAddFlagAttach
 	case "update":
 		flagName = checkUpdateFlagName
@@ -1432,6 +2520,7 @@ func checkAddOrUpdate(mode string, checkIdent string) {
 		flagUpCodes = checkUpdateFlagUpCodes
 		flagUpConfirmationsThreshold = checkUpdateFlagUpConfirmationsThreshold
 		flagDownConfirmationsThreshold = checkUpdateFlagDownConfirmationsThreshold
+		flagDownRegionsThreshold = checkUpdateFlagDownRegionsThreshold
 		flagAttach = checkUpdateFlagAttach
 	}
 
@@ -1714,6 +2803,35 @@ func checkAddOrUpdate(mode string, checkIdent string) {
 		}
 	}
 
+	if mode == "update" && flagDownRegionsThreshold == 0 && currentCheck.DownRegionsThreshold <= len(flagRegions) {
+		// pass
+	} else {
+		// check DownRegionsThreshold does not exceed the number of regions
+		if flagDownRegionsThreshold < 1 || flagDownRegionsThreshold > len(flagRegions) {
+			validate := func(val interface{}) error {
+				var inputInt, _ = strconv.Atoi(val.(string))
+				if inputInt < 1 || inputInt > len(flagRegions) {
+					return errors.New("Down Regions Threshold must be a value between 1 and " + strconv.Itoa(len(flagRegions)))
+				}
+				return nil
+			}
+			defaultDownRegionsThreshold := 1
+			if mode == "update" && currentCheck.DownRegionsThreshold > 0 {
+				// regions may have been removed since, so that the current value no longer fits
+				defaultDownRegionsThreshold = int(math.Min(float64(currentCheck.DownRegionsThreshold), float64(len(flagRegions))))
+			}
+			prompt := &survey.Input{
+				Message: "In how many regions must the check fail to be considered down:",
+				Help:    "Down Regions Threshold must be a value between 1 and " + strconv.Itoa(len(flagRegions)),
+				Default: strconv.Itoa(defaultDownRegionsThreshold),
+			}
+			err := survey.AskOne(prompt, &flagDownRegionsThreshold, survey.WithValidator(validate))
+			if err != nil {
+				handleErr(err)
+			}
+		}
+	}
+
 	spin.Start()
 	defer spin.Stop()
 	spin.Suffix = colorFaint.Sprint(" loading channels...")
@@ -1774,6 +2892,7 @@ func checkAddOrUpdate(mode string, checkIdent string) {
 		UpCodes:                    flagUpCodes,
 		UpConfirmationsThreshold:   flagUpConfirmationsThreshold,
 		DownConfirmationsThreshold: flagDownConfirmationsThreshold,
+		DownRegionsThreshold:       flagDownRegionsThreshold,
 	}
 	postData, err := json.Marshal(check)
 	if err != nil {
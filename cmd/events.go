@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	eventTypeStatusChanged    = "status_changed"
+	eventTypeIncidentOpened   = "incident_opened"
+	eventTypeIncidentResolved = "incident_resolved"
+	eventTypeChannelUsed      = "channel_used"
+	eventsMinInterval         = 5
+	eventsMinOverlap          = time.Minute
+)
+
+// Event is a single line of `binocs events` output, and the data passed to `binocs hooks run` scripts
+type Event struct {
+	Type           string `json:"type"`
+	Time           string `json:"time"`
+	CheckIdent     string `json:"check_ident,omitempty"`
+	CheckName      string `json:"check_name,omitempty"`
+	CheckResource  string `json:"check_resource,omitempty"`
+	Status         string `json:"status,omitempty"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	StatusCode     string `json:"status_code,omitempty"`
+	IncidentIdent  string `json:"incident_ident,omitempty"`
+	IncidentNote   string `json:"incident_note,omitempty"`
+	Duration       string `json:"duration,omitempty"`
+	ChannelIdent   string `json:"channel_ident,omitempty"`
+	ChannelType    string `json:"channel_type,omitempty"`
+	ChannelAlias   string `json:"channel_alias,omitempty"`
+	ChannelHandle  string `json:"channel_handle,omitempty"`
+}
+
+// `events` flags
+var (
+	eventsFlagFollow   bool
+	eventsFlagInterval int
+	eventsFlagPeriod   string
+	eventsFlagFrom     string
+	eventsFlagTo       string
+	eventsFlagSince    string
+)
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+
+	eventsCmd.Flags().BoolVarP(&eventsFlagFollow, "follow", "f", false, "keep running and print new events as they happen")
+	eventsCmd.Flags().IntVar(&eventsFlagInterval, "interval", 30, "how often to poll for new events with --follow, in seconds")
+	eventsCmd.Flags().StringVarP(&eventsFlagPeriod, "period", "p", "", "without --follow, print incident events of the specified period; default day")
+	eventsCmd.Flags().StringVar(&eventsFlagFrom, "from", "", "without --follow, print incident events from this time on, e.g. 2006-01-02T15:04, in your timezone")
+	eventsCmd.Flags().StringVar(&eventsFlagTo, "to", "", "without --follow, print incident events up to this time, e.g. 2006-01-02T15:04, in your timezone; default now")
+	eventsCmd.Flags().StringVar(&eventsFlagSince, "since", "", "without --follow, print incident events of a period ending now, e.g. 90m, 3h, 2d or 1w")
+	eventsCmd.Flags().SortFlags = false
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Print check status changes, incidents and channel notifications as JSON",
+	Long: `
+Print events as newline-delimited JSON, one event per line.
+
+Event types:
+  status_changed       check status changed; "status" and "previous_status" hold the status names
+  incident_opened      incident opened
+  incident_resolved    incident resolved
+  channel_used         notification channel sent a notification
+
+With --follow, the API is polled every --interval seconds and new events are printed as they happen; status and
+channel events are only known while following. Each poll makes three API requests regardless of the number of checks.
+Without --follow, incidents opened or resolved in the specified period are printed.
+`,
+	Example: `  binocs events --follow | jq 'select(.type == "incident_opened")'
+  binocs events --since 3h`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+		spin.Disable()
+
+		encoder := json.NewEncoder(os.Stdout)
+		if !eventsFlagFollow {
+			events, err := fetchIncidentEvents(eventsFlagPeriod, eventsFlagFrom, eventsFlagTo, eventsFlagSince)
+			if err != nil {
+				handleErr(err)
+			}
+			for _, e := range events {
+				_ = encoder.Encode(e)
+			}
+			return
+		}
+
+		if eventsFlagInterval < eventsMinInterval {
+			handleErr(fmt.Errorf("Interval must be at least %d seconds", eventsMinInterval))
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		followEvents(ctx, time.Duration(eventsFlagInterval)*time.Second, func(e Event) {
+			_ = encoder.Encode(e)
+		})
+	},
+}
+
+func fetchIncidentEvents(period, from, to, since string) ([]Event, error) {
+	user, err := fetchUser()
+	if err != nil {
+		return nil, err
+	}
+	tr, err := parseTimeRange(period, from, to, since, periodDay, userLocation(&user))
+	if err != nil {
+		return nil, err
+	}
+	urlValues := url.Values{}
+	tr.setURLValues(&urlValues, false)
+	incidents, err := fetchIncidents(urlValues)
+	if err != nil {
+		return nil, err
+	}
+	events := composeIncidentEvents(incidents, tr.From, tr.To)
+	sortEvents(events)
+	return events, nil
+}
+
+// followEvents calls handle with every new event, polling the API every interval until ctx is done;
+// failed polls are logged and retried, and the events they missed are caught up by the next successful poll
+func followEvents(ctx context.Context, interval time.Duration, handle func(Event)) {
+	poller := newEventPoller(interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		events, err := poller.poll()
+		if err != nil {
+			log.Printf("cannot poll events: %v", err)
+		}
+		for _, e := range events {
+			handle(e)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// eventPoller compares checks and channels with the previous poll, and reads incidents opened or resolved since.
+// Incidents are read from overlapping windows, so that those written late or under clock skew between the client
+// and the API are not missed; seen holds incident events of the overlap, so that they are not repeated
+type eventPoller struct {
+	primed   bool
+	since    time.Time
+	overlap  time.Duration
+	seen     map[string]time.Time
+	checks   map[string]Check
+	channels map[string]Channel
+}
+
+func newEventPoller(interval time.Duration) *eventPoller {
+	overlap := interval
+	if overlap < eventsMinOverlap {
+		overlap = eventsMinOverlap
+	}
+	return &eventPoller{
+		overlap: overlap,
+		seen:    map[string]time.Time{},
+	}
+}
+
+// poll returns events since the previous successful poll; the first poll only records the current state
+func (p *eventPoller) poll() ([]Event, error) {
+	now := time.Now()
+	checks, err := fetchChecks(url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	channels, err := fetchChannels(url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	from := now.Add(-p.overlap)
+	if p.primed {
+		from = p.since.Add(-p.overlap)
+	}
+	urlValues := url.Values{}
+	urlValues.Set("from", from.UTC().Format(time.RFC3339))
+	urlValues.Set("to", now.UTC().Format(time.RFC3339))
+	incidents, err := fetchIncidents(urlValues)
+	if err != nil {
+		return nil, err
+	}
+	incidentEvents := p.unseen(composeIncidentEvents(incidents, from, now))
+	var events []Event
+	if p.primed {
+		events = incidentEvents
+		for _, c := range checks {
+			prev, ok := p.checks[c.Ident]
+			if ok && prev.LastStatus != c.LastStatus {
+				events = append(events, Event{
+					Type:           eventTypeStatusChanged,
+					Time:           now.UTC().Format(time.RFC3339),
+					CheckIdent:     c.Ident,
+					CheckName:      c.Name,
+					CheckResource:  c.Resource,
+					Status:         statusName[c.LastStatus],
+					PreviousStatus: statusName[prev.LastStatus],
+					StatusCode:     c.LastStatusCode,
+				})
+			}
+		}
+		for _, ch := range channels {
+			prev, ok := p.channels[ch.Ident]
+			if ok && ch.UsedCount > prev.UsedCount {
+				t := now.UTC().Format(time.RFC3339)
+				if lastUsed, err := time.Parse(statusHistoryTimeLayout, ch.LastUsed); err == nil {
+					t = lastUsed.UTC().Format(time.RFC3339)
+				}
+				events = append(events, Event{
+					Type:          eventTypeChannelUsed,
+					Time:          t,
+					ChannelIdent:  ch.Ident,
+					ChannelType:   ch.Type,
+					ChannelAlias:  ch.Alias,
+					ChannelHandle: ch.displayHandle(),
+				})
+			}
+		}
+	}
+	p.checks = make(map[string]Check)
+	for _, c := range checks {
+		p.checks[c.Ident] = c
+	}
+	p.channels = make(map[string]Channel)
+	for _, ch := range channels {
+		p.channels[ch.Ident] = ch
+	}
+	p.primed = true
+	p.since = now
+	for key, t := range p.seen {
+		if t.Before(now.Add(-p.overlap)) {
+			delete(p.seen, key)
+		}
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+// unseen drops incident events returned by a previous poll, and remembers the rest
+func (p *eventPoller) unseen(events []Event) []Event {
+	var unseen []Event
+	for _, e := range events {
+		key := e.IncidentIdent + " " + e.Type
+		if _, ok := p.seen[key]; ok {
+			continue
+		}
+		t, _ := time.Parse(time.RFC3339, e.Time)
+		p.seen[key] = t
+		unseen = append(unseen, e)
+	}
+	return unseen
+}
+
+// composeIncidentEvents makes an event of every incident opened or resolved within since and now
+func composeIncidentEvents(incidents []Incident, since, now time.Time) []Event {
+	var events []Event
+	for _, v := range incidents {
+		e := Event{
+			CheckIdent:    v.CheckIdent,
+			CheckName:     v.CheckName,
+			CheckResource: v.CheckResource,
+			IncidentIdent: v.Ident,
+			IncidentNote:  v.IncidentNote,
+		}
+		opened, err := time.Parse(statusHistoryTimeLayout, v.Opened)
+		if err == nil && !opened.Before(since) && opened.Before(now) {
+			e.Type, e.Time = eventTypeIncidentOpened, opened.UTC().Format(time.RFC3339)
+			events = append(events, e)
+		}
+		closed, err := time.Parse(statusHistoryTimeLayout, v.Closed)
+		if err == nil && v.IncidentState != incidentStateOpen && !closed.Before(since) && closed.Before(now) {
+			e.Type, e.Time, e.Duration = eventTypeIncidentResolved, closed.UTC().Format(time.RFC3339), v.Duration
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exporterRegionAll          = "all"
+	exporterMinRefreshInterval = 30
+	exporterMaxConcurrency     = 32
+)
+
+// `exporter` flags
+var (
+	exporterFlagListen          string
+	exporterFlagPeriod          string
+	exporterFlagRefreshInterval int
+	exporterFlagConcurrency     int
+)
+
+func init() {
+	rootCmd.AddCommand(exporterCmd)
+
+	exporterCmd.Flags().StringVar(&exporterFlagListen, "listen", ":9333", "address to serve /metrics on")
+	exporterCmd.Flags().StringVarP(&exporterFlagPeriod, "period", "p", "day", "period of uptime, apdex and mrt values")
+	exporterCmd.Flags().IntVar(&exporterFlagRefreshInterval, "refresh_interval", 60, "how often to refresh metrics from Binocs, in seconds")
+	exporterCmd.Flags().IntVar(&exporterFlagConcurrency, "concurrency", 4, "how many API requests to make at once while refreshing")
+	exporterCmd.Flags().SortFlags = false
+}
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Run a Prometheus exporter",
+	Long: `
+Serve check metrics at /metrics in the Prometheus exposition format.
+
+Metrics are refreshed in the background every --refresh_interval seconds with at most --concurrency API requests at once,
+and scrapes are served from memory, so that scraping does not cause any API requests.
+
+Exported metrics:
+  binocs_check_status              last status: 0 unknown, 1 up (tentative), 2 up, 3 down (tentative), 4 down
+  binocs_check_uptime_percent      uptime over --period
+  binocs_check_apdex               Apdex over --period
+  binocs_check_mrt_seconds         mean response time over --period
+  binocs_check_last_status_code    last HTTP status code
+  binocs_check_incident_open       1 while the check has an open incident
+  binocs_credit_balance            credit balance of your account
+
+Check metrics are labelled with ident, name, protocol and region; uptime, Apdex and MRT are exported for each
+of the check's regions, and for region="all".
+`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+		spin.Disable()
+
+		match, err := regexp.MatchString(validPeriodPattern, exporterFlagPeriod)
+		if err != nil || !match {
+			handleErr(fmt.Errorf("Invalid period provided. Supported periods: hour, day, week, month"))
+		}
+		if exporterFlagRefreshInterval < exporterMinRefreshInterval {
+			handleErr(fmt.Errorf("Refresh interval must be at least %d seconds", exporterMinRefreshInterval))
+		}
+		if exporterFlagConcurrency < 1 || exporterFlagConcurrency > exporterMaxConcurrency {
+			handleErr(fmt.Errorf("Concurrency must be between 1 and %d", exporterMaxConcurrency))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		exporter := &metricsExporter{period: exporterFlagPeriod, concurrency: exporterFlagConcurrency}
+		go exporter.run(ctx, time.Duration(exporterFlagRefreshInterval)*time.Second)
+		exporter.serve(ctx, exporterFlagListen)
+		log.Printf("exporter stopped")
+	},
+}
+
+// metricsExporter keeps the last rendered exposition in memory
+type metricsExporter struct {
+	sync.RWMutex
+	period        string
+	concurrency   int
+	samples       []exporterSample
+	body          []byte
+	refreshed     time.Time
+	refreshErrors int
+}
+
+// exporterSample is a single value of a check in a region, or of the account when check is nil
+type exporterSample struct {
+	name   string
+	check  *Check
+	region string
+	value  float64
+}
+
+func (s exporterSample) labels() string {
+	if s.check == nil {
+		return ""
+	}
+	return fmt.Sprintf(`ident="%s",name="%s",protocol="%s",region="%s"`,
+		escapeLabelValue(s.check.Ident), escapeLabelValue(s.check.Name), escapeLabelValue(s.check.Protocol), escapeLabelValue(s.region))
+}
+
+type exporterJob struct {
+	check  *Check
+	region string
+}
+
+func (e *metricsExporter) run(ctx context.Context, interval time.Duration) {
+	e.refresh()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.refresh()
+		}
+	}
+}
+
+// refresh keeps the last collected check metrics when the API cannot be reached
+func (e *metricsExporter) refresh() {
+	started := time.Now()
+	samples, err := collectCheckSamples(e.period, e.concurrency)
+	e.Lock()
+	defer e.Unlock()
+	if err != nil {
+		e.refreshErrors++
+		log.Printf("cannot refresh metrics: %v", err)
+	} else {
+		e.samples = samples
+		e.refreshed = time.Now()
+	}
+	samples = append([]exporterSample{}, e.samples...)
+	samples = append(samples,
+		exporterSample{name: "binocs_exporter_refresh_errors_total", value: float64(e.refreshErrors)},
+		exporterSample{name: "binocs_exporter_refresh_duration_seconds", value: time.Since(started).Seconds()},
+	)
+	if !e.refreshed.IsZero() {
+		samples = append(samples, exporterSample{name: "binocs_exporter_last_refresh_timestamp_seconds", value: float64(e.refreshed.Unix())})
+	}
+	e.body = renderExposition(samples)
+}
+
+// collectCheckSamples loads checks, open incidents and the user with a few requests, then metrics of each check
+// and each of its regions, with at most concurrency requests at once; metrics that fail to load are left out
+func collectCheckSamples(period string, concurrency int) ([]exporterSample, error) {
+	var samples []exporterSample
+
+	user, err := fetchUser()
+	if err != nil {
+		return samples, err
+	}
+	samples = append(samples, exporterSample{name: "binocs_credit_balance", value: float64(user.CreditBalance)})
+
+	checks, err := fetchChecks(url.Values{})
+	if err != nil {
+		return samples, err
+	}
+	incidents, err := fetchIncidents(url.Values{"state": []string{"open"}})
+	if err != nil {
+		return samples, err
+	}
+	openIncidents := make(map[string]bool)
+	for _, v := range incidents {
+		if v.IncidentState == incidentStateOpen {
+			openIncidents[v.CheckIdent] = true
+		}
+	}
+
+	var jobs []exporterJob
+	for i := range checks {
+		c := &checks[i]
+		samples = append(samples, exporterSample{"binocs_check_status", c, exporterRegionAll, float64(c.LastStatus)})
+		var incidentOpen float64
+		if openIncidents[c.Ident] {
+			incidentOpen = 1
+		}
+		samples = append(samples, exporterSample{"binocs_check_incident_open", c, exporterRegionAll, incidentOpen})
+		if code, err := strconv.Atoi(strings.SplitN(c.LastStatusCode, " ", 2)[0]); err == nil {
+			samples = append(samples, exporterSample{"binocs_check_last_status_code", c, exporterRegionAll, float64(code)})
+		}
+		jobs = append(jobs, exporterJob{c, exporterRegionAll})
+		for _, r := range c.Regions {
+			jobs = append(jobs, exporterJob{c, r})
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan exporterJob)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				urlValues := url.Values{}
+				urlValues.Set("period", period)
+				if job.region != exporterRegionAll {
+					urlValues.Set("region", job.region)
+				}
+				metrics, err := fetchMetrics(job.check.Ident, &urlValues)
+				if err != nil {
+					log.Printf("cannot refresh metrics of check %s in region %s: %v", job.check.Ident, job.region, err)
+					continue
+				}
+				mu.Lock()
+				samples = appendExporterSample(samples, "binocs_check_uptime_percent", job, metrics.Uptime)
+				samples = appendExporterSample(samples, "binocs_check_apdex", job, metrics.Apdex)
+				samples = appendExporterSample(samples, "binocs_check_mrt_seconds", job, metrics.MRT)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+	return samples, nil
+}
+
+func appendExporterSample(samples []exporterSample, name string, job exporterJob, value string) []exporterSample {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return samples
+	}
+	return append(samples, exporterSample{name, job.check, job.region, v})
+}
+
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+var exporterHelp = map[string]string{
+	"binocs_check_status":                            "Last check status: 0 unknown, 1 up (tentative), 2 up, 3 down (tentative), 4 down.",
+	"binocs_check_uptime_percent":                    "Check uptime over the period, in percent.",
+	"binocs_check_apdex":                             "Check Apdex over the period.",
+	"binocs_check_mrt_seconds":                       "Check mean response time over the period, in seconds.",
+	"binocs_check_last_status_code":                  "Last HTTP status code of the check.",
+	"binocs_check_incident_open":                     "Whether the check has an open incident.",
+	"binocs_credit_balance":                          "Credit balance of the account.",
+	"binocs_exporter_refresh_errors_total":           "Number of failed metric refreshes.",
+	"binocs_exporter_last_refresh_timestamp_seconds": "Time of the last successful metric refresh.",
+	"binocs_exporter_refresh_duration_seconds":       "Duration of the last metric refresh.",
+}
+
+// renderExposition writes samples grouped by metric name, in a stable order
+func renderExposition(samples []exporterSample) []byte {
+	sort.SliceStable(samples, func(i, j int) bool {
+		if samples[i].name != samples[j].name {
+			return samples[i].name < samples[j].name
+		}
+		return samples[i].labels() < samples[j].labels()
+	})
+	var buf bytes.Buffer
+	var last string
+	for _, s := range samples {
+		if s.name != last {
+			metricType := "gauge"
+			if strings.HasSuffix(s.name, "_total") {
+				metricType = "counter"
+			}
+			fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", s.name, exporterHelp[s.name], s.name, metricType)
+			last = s.name
+		}
+		if labels := s.labels(); len(labels) > 0 {
+			fmt.Fprintf(&buf, "%s{%s} %s\n", s.name, labels, strconv.FormatFloat(s.value, 'g', -1, 64))
+		} else {
+			fmt.Fprintf(&buf, "%s %s\n", s.name, strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+	return buf.Bytes()
+}
+
+func (e *metricsExporter) serve(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		e.RLock()
+		body := e.body
+		e.RUnlock()
+		if body == nil {
+			http.Error(w, "metrics not loaded yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(body)
+	})
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	log.Printf("serving metrics at http://%s/metrics", addr)
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		handleErr(err)
+	}
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	hookEventAll      = "all"
+	hooksMaxOutputLen = 4096
+)
+
+// hookEvents are the names accepted by `hooks run --on`, besides event types
+var hookEvents = map[string]func(e Event) bool{
+	hookEventAll: func(e Event) bool { return true },
+	"status":     func(e Event) bool { return e.Type == eventTypeStatusChanged },
+	"up":         func(e Event) bool { return e.Type == eventTypeStatusChanged && e.Status == statusNameUp },
+	"down":       func(e Event) bool { return e.Type == eventTypeStatusChanged && e.Status == statusNameDown },
+	"opened":     func(e Event) bool { return e.Type == eventTypeIncidentOpened },
+	"resolved":   func(e Event) bool { return e.Type == eventTypeIncidentResolved },
+	"channel":    func(e Event) bool { return e.Type == eventTypeChannelUsed },
+}
+
+// `hooks run` flags
+var (
+	hooksRunFlagOn          []string
+	hooksRunFlagInterval    int
+	hooksRunFlagTimeout     int
+	hooksRunFlagConcurrency int
+)
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksRunCmd)
+
+	hooksRunCmd.Flags().StringArrayVar(&hooksRunFlagOn, "on", []string{}, "command to run on an event, e.g. \"down=./restart.sh\"; can be repeated")
+	hooksRunCmd.Flags().IntVar(&hooksRunFlagInterval, "interval", 30, "how often to poll for new events, in seconds")
+	hooksRunCmd.Flags().IntVar(&hooksRunFlagTimeout, "timeout", 60, "how long a command may run before it is killed, in seconds")
+	hooksRunCmd.Flags().IntVar(&hooksRunFlagConcurrency, "concurrency", 4, "how many commands may run at once; further commands wait")
+	hooksRunCmd.Flags().SortFlags = false
+}
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Run local commands on events",
+	Long: `
+Run local commands on check status changes, incidents and channel notifications.
+`,
+	DisableAutoGenTag: true,
+}
+
+var hooksRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run local commands on events, until stopped",
+	Long: `
+Follow events the way "binocs events --follow" does, and run a command for each event it is registered for with --on.
+
+Events:
+  up          check status changed to UP
+  down        check status changed to DOWN
+  status      any check status change
+  opened      incident opened
+  resolved    incident resolved
+  channel     notification channel sent a notification
+  all         any event
+
+Event types, e.g. status_changed or incident_opened, can be used, too.
+
+Commands run in a shell. The event is passed on stdin as a JSON line, and in environment variables named after
+its fields, e.g. BINOCS_TYPE, BINOCS_CHECK_IDENT, BINOCS_STATUS or BINOCS_INCIDENT_IDENT.
+Commands running longer than --timeout seconds are killed; at most --concurrency commands run at once.
+`,
+	Example: `  binocs hooks run --on down=./restart.sh --on resolved=./notify.sh
+  binocs hooks run --on "opened=curl -s -d @- https://chat.example.com/hook" --timeout 10`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+		spin.Disable()
+
+		hooks, err := parseHooks(hooksRunFlagOn)
+		if err != nil {
+			handleErr(err)
+		}
+		if hooksRunFlagInterval < eventsMinInterval {
+			handleErr(fmt.Errorf("Interval must be at least %d seconds", eventsMinInterval))
+		}
+		if hooksRunFlagTimeout < 1 {
+			handleErr(fmt.Errorf("Timeout must be at least 1 second"))
+		}
+		if hooksRunFlagConcurrency < 1 {
+			handleErr(fmt.Errorf("Concurrency must be at least 1"))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		runner := &hookRunner{
+			hooks:     hooks,
+			timeout:   time.Duration(hooksRunFlagTimeout) * time.Second,
+			semaphore: make(chan struct{}, hooksRunFlagConcurrency),
+		}
+		log.Printf("running %d hook(s), polling for events every %ds", len(hooks), hooksRunFlagInterval)
+		followEvents(ctx, time.Duration(hooksRunFlagInterval)*time.Second, runner.handle)
+		log.Printf("waiting for running hooks")
+		runner.Wait()
+		log.Printf("hooks stopped")
+	},
+}
+
+// hook is a command registered for an event with --on
+type hook struct {
+	event   string
+	command string
+}
+
+func parseHooks(on []string) ([]hook, error) {
+	var hooks []hook
+	if len(on) == 0 {
+		return hooks, fmt.Errorf("Provide at least one --on flag, e.g. --on down=./restart.sh")
+	}
+	var events []string
+	for k := range hookEvents {
+		events = append(events, k)
+	}
+	sort.Strings(events)
+	for _, v := range on {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 || len(strings.TrimSpace(kv[1])) == 0 {
+			return hooks, fmt.Errorf("Invalid --on value provided: %s; use e.g. down=./restart.sh", v)
+		}
+		event := strings.ToLower(strings.TrimSpace(kv[0]))
+		if _, ok := hookEvents[event]; !ok && !util.StringInSlice(event, []string{eventTypeStatusChanged, eventTypeIncidentOpened, eventTypeIncidentResolved, eventTypeChannelUsed}) {
+			return hooks, fmt.Errorf("Invalid event provided: %s. Supported events: %s", event, strings.Join(events, ", "))
+		}
+		hooks = append(hooks, hook{event: event, command: strings.TrimSpace(kv[1])})
+	}
+	return hooks, nil
+}
+
+func (h hook) matches(e Event) bool {
+	if match, ok := hookEvents[h.event]; ok {
+		return match(e)
+	}
+	return h.event == e.Type
+}
+
+// hookRunner starts commands of matching hooks in the background, at most cap(semaphore) at once
+type hookRunner struct {
+	sync.WaitGroup
+	hooks     []hook
+	timeout   time.Duration
+	semaphore chan struct{}
+}
+
+func (r *hookRunner) handle(e Event) {
+	for _, h := range r.hooks {
+		if !h.matches(e) {
+			continue
+		}
+		r.Add(1)
+		go func(h hook) {
+			defer r.Done()
+			r.semaphore <- struct{}{}
+			defer func() { <-r.semaphore }()
+			r.run(h, e)
+		}(h)
+	}
+}
+
+func (r *hookRunner) run(h hook, e Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("cannot encode event: %v", err)
+		return
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", h.command)
+	} else {
+		cmd = exec.Command("sh", "-c", h.command)
+	}
+	prepareHookCommand(cmd)
+	var output bytes.Buffer
+	cmd.Env = append(os.Environ(), eventEnv(data)...)
+	cmd.Stdin = bytes.NewReader(append(data, '\n'))
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	started := time.Now()
+	err = cmd.Start()
+	if err != nil {
+		log.Printf("hook %q on %s cannot start: %v", h.command, e.Type, err)
+		return
+	}
+	timer := time.AfterFunc(r.timeout, func() {
+		killHookCommand(cmd)
+	})
+	err = cmd.Wait()
+	timedOut := !timer.Stop()
+	elapsed := time.Since(started).Round(time.Millisecond)
+	switch {
+	case timedOut:
+		log.Printf("hook %q on %s timed out after %v", h.command, e.Type, r.timeout)
+	case err != nil:
+		log.Printf("hook %q on %s failed after %v: %v", h.command, e.Type, elapsed, err)
+	default:
+		log.Printf("hook %q on %s done in %v", h.command, e.Type, elapsed)
+	}
+	out := output.Bytes()
+	if len(out) > hooksMaxOutputLen {
+		out = append(out[:hooksMaxOutputLen], []byte("...")...)
+	}
+	if trimmed := strings.TrimSpace(string(out)); len(trimmed) > 0 {
+		log.Printf("hook %q output: %s", h.command, trimmed)
+	}
+}
+
+// eventEnv turns JSON fields of an event into BINOCS_<FIELD> environment variables
+func eventEnv(data []byte) []string {
+	fields := map[string]string{}
+	_ = json.Unmarshal(data, &fields)
+	var env []string
+	for k, v := range fields {
+		env = append(env, "BINOCS_"+strings.ToUpper(k)+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
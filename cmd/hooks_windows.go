@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package cmd
+
+import (
+	"os/exec"
+)
+
+func prepareHookCommand(cmd *exec.Cmd) {}
+
+func killHookCommand(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
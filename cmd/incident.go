@@ -3,13 +3,18 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/AlecAivazis/survey/v2"
 	util "github.com/automato-io/binocs-cli/util"
 	"github.com/automato-io/tablewriter"
 	"github.com/fatih/color"
@@ -31,9 +36,15 @@ type Incident struct {
 	Closed        string    `json:"closed"`
 	Duration      string    `json:"duration"`
 	ResponseCodes []string  `json:"response_codes"`
+	DownRegions   []string  `json:"down_regions,omitempty"`
 	Requests      []Request `json:"requests"`
 }
 
+// IncidentNoteUpdate struct is used to provide an incident with a note
+type IncidentNoteUpdate struct {
+	IncidentNote string `json:"incident_note"`
+}
+
 // Request struct
 type Request struct {
 	Region             string  `json:"region"`
@@ -56,15 +67,20 @@ type Timings struct {
 }
 
 var (
-	incidentInspectFlagWatch bool
+	incidentInspectFlagWatch    bool
+	incidentInspectFlagInterval int
 )
 
 // `incident ls` flags
 var (
 	incidentListFlagCheck    string
+	incidentListFlagFrom     string
+	incidentListFlagTo       string
+	incidentListFlagSince    string
 	incidentListFlagOpen     bool
 	incidentListFlagResolved bool
 	incidentListFlagWatch    bool
+	incidentListFlagInterval int
 )
 
 // `incident update` flags
@@ -74,6 +90,7 @@ var (
 
 const (
 	validCheckIdentPattern = `^[a-f0-9]{7}$`
+	incidentNoteMaxLength  = 1000
 )
 
 func init() {
@@ -85,16 +102,25 @@ func init() {
 	incidentCmd.AddCommand(incidentListCmd)
 	incidentCmd.AddCommand(incidentUpdateCmd)
 
-	incidentInspectCmd.Flags().BoolVar(&incidentInspectFlagWatch, "watch", false, "run in cell view and refresh binocs output every 5 seconds")
+	incidentInspectCmd.Flags().BoolVar(&incidentInspectFlagWatch, "watch", false, "run in cell view and refresh binocs output every --interval seconds")
+	incidentInspectCmd.Flags().IntVar(&incidentInspectFlagInterval, "interval", defaultWatchInterval, "refresh interval of --watch, in seconds")
 
 	incidentsCmd.Flags().StringVarP(&incidentListFlagCheck, "check", "c", "", "list only incidents of this check")
+	incidentsCmd.Flags().StringVar(&incidentListFlagFrom, "from", "", "list only incidents open at or after this time, e.g. 2006-01-02T15:04, in your timezone")
+	incidentsCmd.Flags().StringVar(&incidentListFlagTo, "to", "", "list only incidents open at or before this time, e.g. 2006-01-02T15:04, in your timezone; default now")
+	incidentsCmd.Flags().StringVar(&incidentListFlagSince, "since", "", "list only incidents open within a period ending now, e.g. 90m, 3h, 2d or 1w")
 	incidentsCmd.Flags().BoolVar(&incidentListFlagOpen, "open", false, "list only open incidents")
 	incidentsCmd.Flags().BoolVar(&incidentListFlagResolved, "resolved", false, "list only resolved incidents")
-	incidentsCmd.Flags().BoolVar(&incidentListFlagWatch, "watch", false, "run in cell view and refresh binocs output every 5 seconds")
+	incidentsCmd.Flags().BoolVar(&incidentListFlagWatch, "watch", false, "run in cell view and refresh binocs output every --interval seconds")
+	incidentsCmd.Flags().IntVar(&incidentListFlagInterval, "interval", defaultWatchInterval, "refresh interval of --watch, in seconds")
 	incidentListCmd.Flags().StringVarP(&incidentListFlagCheck, "check", "c", "", "list only incidents of this check")
+	incidentListCmd.Flags().StringVar(&incidentListFlagFrom, "from", "", "list only incidents open at or after this time, e.g. 2006-01-02T15:04, in your timezone")
+	incidentListCmd.Flags().StringVar(&incidentListFlagTo, "to", "", "list only incidents open at or before this time, e.g. 2006-01-02T15:04, in your timezone; default now")
+	incidentListCmd.Flags().StringVar(&incidentListFlagSince, "since", "", "list only incidents open within a period ending now, e.g. 90m, 3h, 2d or 1w")
 	incidentListCmd.Flags().BoolVar(&incidentListFlagOpen, "open", false, "list only open incidents")
 	incidentListCmd.Flags().BoolVar(&incidentListFlagResolved, "resolved", false, "list only resolved incidents")
-	incidentListCmd.Flags().BoolVar(&incidentListFlagWatch, "watch", false, "run in cell view and refresh binocs output every 5 seconds")
+	incidentListCmd.Flags().BoolVar(&incidentListFlagWatch, "watch", false, "run in cell view and refresh binocs output every --interval seconds")
+	incidentListCmd.Flags().IntVar(&incidentListFlagInterval, "interval", defaultWatchInterval, "refresh interval of --watch, in seconds")
 
 	incidentUpdateCmd.Flags().StringVarP(&incidentUpdateFlagNote, "note", "n", "", "incident note")
 }
@@ -129,202 +155,150 @@ View incident details, notes and associated requests.
 		util.VerifyAuthenticated()
 
 		if incidentInspectFlagWatch {
-			runAsWatch()
+			runAsWatch(func(w io.Writer) error {
+				return renderIncidentInspect(w, args[0])
+			}, time.Duration(incidentInspectFlagInterval)*time.Second)
 			return
 		}
 
-		spin.Start()
-		defer spin.Stop()
-		spin.Suffix = colorFaint.Sprint(" loading incident...")
-		user, err := fetchUser()
-		if err != nil {
-			handleErr(err)
-		}
-		respData, err := util.BinocsAPI("/incidents/"+args[0], http.MethodGet, []byte{})
+		err := renderIncidentInspect(os.Stdout, args[0])
 		if err != nil {
 			handleErr(err)
 		}
-		var respJSON Incident
-		err = json.Unmarshal(respData, &respJSON)
-		if err != nil {
-			handleErr(err)
-		}
-		var checkName string
-		if respJSON.CheckName == "" {
-			checkName = "-"
-		} else {
-			checkName = respJSON.CheckName
-		}
+	},
+}
 
-		// Table "main"
+// renderIncidentInspect writes the `incident inspect` tables to w
+func renderIncidentInspect(w io.Writer, ident string) error {
+	spin.Start()
+	defer spin.Stop()
+	spin.Suffix = colorFaint.Sprint(" loading incident...")
+	user, err := fetchUser()
+	if err != nil {
+		return err
+	}
+	respData, err := util.BinocsAPI("/incidents/"+ident, http.MethodGet, []byte{})
+	if err != nil {
+		return err
+	}
+	var respJSON Incident
+	err = json.Unmarshal(respData, &respJSON)
+	if err != nil {
+		return err
+	}
+	var checkName string
+	if respJSON.CheckName == "" {
+		checkName = "-"
+	} else {
+		checkName = respJSON.CheckName
+	}
 
-		var stateSnippet string
-		switch respJSON.IncidentState {
-		case incidentStateOpen:
-			stateSnippet = color.YellowString(strings.ToUpper(respJSON.IncidentState))
-		case incidentStateResolved:
-			stateSnippet = color.GreenString(strings.ToUpper(respJSON.IncidentState))
-		}
+	// Table "main"
 
-		var openedSnippet = respJSON.Opened
+	var stateSnippet string
+	switch respJSON.IncidentState {
+	case incidentStateOpen:
+		stateSnippet = color.YellowString(strings.ToUpper(respJSON.IncidentState))
+	case incidentStateResolved:
+		stateSnippet = color.GreenString(strings.ToUpper(respJSON.IncidentState))
+	}
 
-		var closedSnippet = respJSON.Closed
-		if closedSnippet == "" {
-			closedSnippet = "-"
-		}
+	var openedSnippet = respJSON.Opened
 
-		tableMainIncidentCellContent := colorBold.Sprint(`ID: `) + respJSON.Ident + "\n" +
-			colorBold.Sprint(`Status: `) + stateSnippet + "\n" +
-			colorBold.Sprint(`Opened: `) + openedSnippet + "\n" +
-			colorBold.Sprint(`Closed: `) + closedSnippet + "\n" +
-			colorBold.Sprint(`Duration: `) + util.OutputDurationWithDays(respJSON.Duration)
+	var closedSnippet = respJSON.Closed
+	if closedSnippet == "" {
+		closedSnippet = "-"
+	}
 
-		tableMainCheckCellContent := colorBold.Sprint(`ID: `) + respJSON.CheckIdent + "\n" +
-			colorBold.Sprint("Name: ") + checkName + "\n" +
-			colorBold.Sprint("URL: ") + respJSON.CheckResource
+	var downRegionsSnippet string
+	if len(respJSON.DownRegions) > 0 {
+		downRegionsSnippet = "\n" + colorBold.Sprint(`Down in: `) + strings.Join(getRegionAliasesByIds(respJSON.DownRegions), ", ")
+	}
 
-		tableMainNotesCellContent := respJSON.IncidentNote
-		if tableMainNotesCellContent == "" {
-			tableMainNotesCellContent = colorFaint.Sprint("-")
-		}
+	tableMainIncidentCellContent := colorBold.Sprint(`ID: `) + respJSON.Ident + "\n" +
+		colorBold.Sprint(`Status: `) + stateSnippet + "\n" +
+		colorBold.Sprint(`Opened: `) + openedSnippet + "\n" +
+		colorBold.Sprint(`Closed: `) + closedSnippet + "\n" +
+		colorBold.Sprint(`Duration: `) + util.OutputDurationWithDays(respJSON.Duration) +
+		downRegionsSnippet
 
-		tableMainColumnDefinitions := []tableColumnDefinition{
-			{
-				Header:    "CHECK",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "INCIDENT",
-				Priority:  1,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "NOTES",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-		}
+	tableMainCheckCellContent := colorBold.Sprint(`ID: `) + respJSON.CheckIdent + "\n" +
+		colorBold.Sprint("Name: ") + checkName + "\n" +
+		colorBold.Sprint("URL: ") + respJSON.CheckResource
 
-		var tableMainData [][]string
-		tableMainData = append(tableMainData, []string{tableMainCheckCellContent, tableMainIncidentCellContent, tableMainNotesCellContent})
-		tableMain := composeTable(tableMainData, tableMainColumnDefinitions)
-
-		// Table "requests"
-
-		tableRequestsColumnDefinitions := []tableColumnDefinition{
-			{
-				Header:    "CHECKED AT",
-				Priority:  1,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "FROM",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "RESPONSE",
-				Priority:  1,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "RESPONSE TIME",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-			{
-				Header:    "DNS LOOKUP",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-			{
-				Header:    "CONNECTION",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-			{
-				Header:    "TLS",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-			{
-				Header:    "WAITING",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-			{
-				Header:    "TRANSFER",
-				Priority:  4,
-				Alignment: tablewriter.ALIGN_RIGHT,
-			},
-		}
+	tableMainNotesCellContent := respJSON.IncidentNote
+	if tableMainNotesCellContent == "" {
+		tableMainNotesCellContent = colorFaint.Sprint("-")
+	}
 
-		var tableRequests *tablewriter.Table
-		var tableRequestsData [][]string
-		if len(respJSON.Requests) > 0 {
-			var placeholder = "·"
-			var fieldLengthCheckedFrom int
-			for _, request := range respJSON.Requests {
-				if fieldLengthCheckedFrom < len(regionAliases[request.Region]) {
-					fieldLengthCheckedFrom = len(regionAliases[request.Region])
-				}
+	tableMainColumnDefinitions := []tableColumnDefinition{
+		{
+			Header:    "CHECK",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "INCIDENT",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "NOTES",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+	}
+
+	var tableMainData [][]string
+	tableMainData = append(tableMainData, []string{tableMainCheckCellContent, tableMainIncidentCellContent, tableMainNotesCellContent})
+	tableMain := composeTableTo(w, tableMainData, tableMainColumnDefinitions)
+
+	// Table "requests"
+
+	tableRequestsColumnDefinitions := requestsTableColumnDefinitions()
+
+	var tableRequests *tablewriter.Table
+	var tableRequestsData [][]string
+	if len(respJSON.Requests) > 0 {
+		var placeholder = "·"
+		var fieldLengthCheckedFrom int
+		for _, request := range respJSON.Requests {
+			if fieldLengthCheckedFrom < len(regionAliases[request.Region]) {
+				fieldLengthCheckedFrom = len(regionAliases[request.Region])
 			}
-			for _, request := range respJSON.Requests {
-				if strings.Contains(request.Timestamp, "0001") {
-					sameSameSpace := len(request.Timestamp) - len(request.RequestResource+" requests") - 2
-					sameSamePlaceholders := [2]int{0, 0}
-					if sameSameSpace > 0 {
-						if sameSameSpace%2 == 1 {
-							sameSamePlaceholders[0] = sameSameSpace/2 + 1
-							sameSamePlaceholders[1] = sameSameSpace / 2
-						} else {
-							sameSamePlaceholders[0] = sameSameSpace / 2
-							sameSamePlaceholders[1] = sameSameSpace / 2
-						}
-					}
-					sameSame := fmt.Sprintf("%s %s requests %s", strings.Repeat(placeholder, sameSamePlaceholders[0]), request.RequestResource, strings.Repeat(placeholder, sameSamePlaceholders[1]))
-					tableRequestsData = append(tableRequestsData, []string{sameSame, strings.Repeat(placeholder, fieldLengthCheckedFrom), request.ResponseStatusCode, strings.Repeat(placeholder, 7), colorFaint.Sprint(strings.Repeat(placeholder, 7)),
-						colorFaint.Sprint(strings.Repeat(placeholder, 7)), colorFaint.Sprint(strings.Repeat(placeholder, 7)), colorFaint.Sprint(strings.Repeat(placeholder, 7)), colorFaint.Sprint(strings.Repeat(placeholder, 7))})
-				} else {
-					var responseTime, timingsDNSLookup, timingsConnection, timingsTLS, timingsWait, timingsTransfer string
-					var timingsDNSLookupFloat, timingsConnectionFloat, timingsTLSFloat, timingsWaitFloat, timingsTransferFloat float64
-					if request.Timings.DSNLookup != "nil" {
-						timingsDNSLookup = fmt.Sprintf("%s s", request.Timings.DSNLookup)
-						timingsConnection = fmt.Sprintf("%s s", request.Timings.Connection)
-						timingsTLS = fmt.Sprintf("%s s", request.Timings.TLS)
-						timingsWait = fmt.Sprintf("%s s", request.Timings.Wait)
-						timingsTransfer = fmt.Sprintf("%s s", request.Timings.Transfer)
-						timingsDNSLookupFloat, _ = strconv.ParseFloat(request.Timings.DSNLookup, 32)
-						timingsConnectionFloat, _ = strconv.ParseFloat(request.Timings.Connection, 32)
-						timingsTLSFloat, _ = strconv.ParseFloat(request.Timings.TLS, 32)
-						timingsWaitFloat, _ = strconv.ParseFloat(request.Timings.Wait, 32)
-						timingsTransferFloat, _ = strconv.ParseFloat(request.Timings.Transfer, 32)
-						responseTime = fmt.Sprintf("%.3f s", timingsDNSLookupFloat+timingsConnectionFloat+timingsTLSFloat+timingsWaitFloat+timingsTransferFloat)
+		}
+		for _, request := range respJSON.Requests {
+			if strings.Contains(request.Timestamp, "0001") {
+				sameSameSpace := len(request.Timestamp) - len(request.RequestResource+" requests") - 2
+				sameSamePlaceholders := [2]int{0, 0}
+				if sameSameSpace > 0 {
+					if sameSameSpace%2 == 1 {
+						sameSamePlaceholders[0] = sameSameSpace/2 + 1
+						sameSamePlaceholders[1] = sameSameSpace / 2
 					} else {
-						responseTime = "n/a"
-						timingsDNSLookup = "n/a"
-						timingsConnection = "n/a"
-						timingsTLS = "n/a"
-						timingsWait = "n/a"
-						timingsTransfer = "n/a"
+						sameSamePlaceholders[0] = sameSameSpace / 2
+						sameSamePlaceholders[1] = sameSameSpace / 2
 					}
-					tableRequestsData = append(tableRequestsData, []string{request.Timestamp, regionAliases[request.Region], request.ResponseStatusCode, responseTime, colorFaint.Sprint(timingsDNSLookup),
-						colorFaint.Sprint(timingsConnection), colorFaint.Sprint(timingsTLS), colorFaint.Sprint(timingsWait), colorFaint.Sprint(timingsTransfer)})
 				}
+				sameSame := fmt.Sprintf("%s %s requests %s", strings.Repeat(placeholder, sameSamePlaceholders[0]), request.RequestResource, strings.Repeat(placeholder, sameSamePlaceholders[1]))
+				tableRequestsData = append(tableRequestsData, []string{sameSame, strings.Repeat(placeholder, fieldLengthCheckedFrom), request.ResponseStatusCode, strings.Repeat(placeholder, 7), colorFaint.Sprint(strings.Repeat(placeholder, 7)),
+					colorFaint.Sprint(strings.Repeat(placeholder, 7)), colorFaint.Sprint(strings.Repeat(placeholder, 7)), colorFaint.Sprint(strings.Repeat(placeholder, 7)), colorFaint.Sprint(strings.Repeat(placeholder, 7))})
+			} else {
+				tableRequestsData = append(tableRequestsData, makeRequestTableRow(request))
 			}
-			tableRequests = composeTable(tableRequestsData, tableRequestsColumnDefinitions)
 		}
+		tableRequests = composeTableTo(w, tableRequestsData, tableRequestsColumnDefinitions)
+	}
 
-		spin.Stop()
-		if user.CreditBalance == 0 {
-			printZeroCreditsWarning()
-		}
-		tableMain.Render()
-		if len(respJSON.Requests) > 0 {
-			tableRequests.Render()
-		}
-	},
+	spin.Stop()
+	if user.CreditBalance == 0 {
+		printZeroCreditsWarning(w)
+	}
+	tableMain.Render()
+	if len(respJSON.Requests) > 0 {
+		tableRequests.Render()
+	}
+	return nil
 }
 
 var incidentListCmd = &cobra.Command{
@@ -339,118 +313,133 @@ List all past and current incidents.
 	Run: func(cmd *cobra.Command, args []string) {
 		util.VerifyAuthenticated()
 
+		if incidentListFlagOpen && incidentListFlagResolved {
+			handleErr(fmt.Errorf("Cannot use --open and --resolved flags together"))
+		}
+
 		if incidentListFlagWatch {
-			runAsWatch()
+			runAsWatch(renderIncidentList, time.Duration(incidentListFlagInterval)*time.Second)
 			return
 		}
 
-		spin.Start()
-		defer spin.Stop()
-		spin.Suffix = colorFaint.Sprint(" loading incidents...")
-		user, err := fetchUser()
+		err := renderIncidentList(os.Stdout)
 		if err != nil {
 			handleErr(err)
 		}
-		urlValues := url.Values{
-			"period": []string{"all"},
-		}
-		match, err := regexp.MatchString(validCheckIdentPattern, incidentListFlagCheck)
-		if err == nil && match {
-			urlValues.Set("check", incidentListFlagCheck)
-		}
-		if incidentListFlagOpen && incidentListFlagResolved {
-			spin.Stop()
-			handleErr(fmt.Errorf("Cannot use --open and --resolved flags together"))
-		}
-		if incidentListFlagOpen {
-			urlValues.Set("state", "open")
+	},
+}
+
+// renderIncidentList writes the `incident list` table to w
+func renderIncidentList(w io.Writer) error {
+
+	spin.Start()
+	defer spin.Stop()
+	spin.Suffix = colorFaint.Sprint(" loading incidents...")
+	user, err := fetchUser()
+	if err != nil {
+		return err
+	}
+	urlValues := url.Values{
+		"period": []string{"all"},
+	}
+	match, err := regexp.MatchString(validCheckIdentPattern, incidentListFlagCheck)
+	if err == nil && match {
+		urlValues.Set("check", incidentListFlagCheck)
+	}
+	if incidentListFlagOpen {
+		urlValues.Set("state", "open")
+	}
+	if incidentListFlagResolved {
+		urlValues.Set("state", "resolved")
+	}
+	tr, err := parseTimeRange("", incidentListFlagFrom, incidentListFlagTo, incidentListFlagSince, "", userLocation(&user))
+	if err != nil {
+		return err
+	}
+	tr.setURLValues(&urlValues, false)
+
+	incidents, err := fetchIncidents(urlValues)
+	if err != nil {
+		return err
+	}
+	var tableData [][]string
+	for _, v := range incidents {
+		var identSnippet, checkNameSnippet, stateSnippet, closedSnippet string
+		identSnippet = colorBold.Sprint(v.Ident)
+		if v.CheckName == "" {
+			checkNameSnippet = colorFaint.Sprint("-")
+		} else {
+			checkNameSnippet = colorBold.Sprint(v.CheckName)
 		}
-		if incidentListFlagResolved {
-			urlValues.Set("state", "resolved")
+		switch v.IncidentState {
+		case incidentStateOpen:
+			stateSnippet = color.YellowString(strings.ToUpper(v.IncidentState))
+		case incidentStateResolved:
+			stateSnippet = color.GreenString(strings.ToUpper(v.IncidentState))
 		}
-
-		incidents, err := fetchIncidents(urlValues)
-		if err != nil {
-			handleErr(err)
+		if v.Closed == "" {
+			closedSnippet = colorFaint.Sprint("-")
+		} else {
+			closedSnippet = v.Closed
 		}
-		var tableData [][]string
-		for _, v := range incidents {
-			var identSnippet, checkNameSnippet, stateSnippet, closedSnippet string
-			identSnippet = colorBold.Sprint(v.Ident)
-			if v.CheckName == "" {
-				checkNameSnippet = colorFaint.Sprint("-")
-			} else {
-				checkNameSnippet = colorBold.Sprint(v.CheckName)
-			}
-			switch v.IncidentState {
-			case incidentStateOpen:
-				stateSnippet = color.YellowString(strings.ToUpper(v.IncidentState))
-			case incidentStateResolved:
-				stateSnippet = color.GreenString(strings.ToUpper(v.IncidentState))
-			}
-			if v.Closed == "" {
-				closedSnippet = colorFaint.Sprint("-")
-			} else {
-				closedSnippet = v.Closed
-			}
 
-			tableRow := []string{
-				identSnippet, v.CheckIdent, checkNameSnippet, util.Ellipsis(v.CheckResource, 50), stateSnippet, v.Opened, closedSnippet, util.OutputDurationWithDays(v.Duration),
-			}
-			tableData = append(tableData, tableRow)
+		tableRow := []string{
+			identSnippet, v.CheckIdent, checkNameSnippet, util.Ellipsis(v.CheckResource, 50), stateSnippet, v.Opened, closedSnippet, util.OutputDurationWithDays(v.Duration),
 		}
+		tableData = append(tableData, tableRow)
+	}
 
-		columnDefinitions := []tableColumnDefinition{
-			{
-				Header:    "INCIDENT ID",
-				Priority:  1,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "CHECK ID",
-				Priority:  1,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "CHECK NAME",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "URL/HOST",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "STATE",
-				Priority:  1,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "OPENED",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "CLOSED",
-				Priority:  2,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-			{
-				Header:    "DURATION",
-				Priority:  3,
-				Alignment: tablewriter.ALIGN_LEFT,
-			},
-		}
+	columnDefinitions := []tableColumnDefinition{
+		{
+			Header:    "INCIDENT ID",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "CHECK ID",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "CHECK NAME",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "URL/HOST",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "STATE",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "OPENED",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "CLOSED",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "DURATION",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+	}
 
-		table := composeTable(tableData, columnDefinitions)
+	table := composeTableTo(w, tableData, columnDefinitions)
 
-		spin.Stop()
-		if user.CreditBalance == 0 {
-			printZeroCreditsWarning()
-		}
-		table.Render()
-	},
+	spin.Stop()
+	if user.CreditBalance == 0 {
+		printZeroCreditsWarning(w)
+	}
+	table.Render()
+	return nil
 }
 
 var incidentUpdateCmd = &cobra.Command{
@@ -459,17 +448,143 @@ var incidentUpdateCmd = &cobra.Command{
 	Long: `
 Provide incident with a note. This note would be visible on incident page.
 
+While the incident is open, the note is also added to alerts of PagerDuty and Opsgenie channels attached to the check.
+
 This command is interactive and asks user for parameters that were not provided as flags.
 `,
+	Example:           `  binocs incident update x1y2z3 --note "DB failover, replica lag"`,
 	Args:              cobra.ExactArgs(1),
 	DisableAutoGenTag: true,
 	Run: func(cmd *cobra.Command, args []string) {
 		util.VerifyAuthenticated()
 
-		// @todo implement
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading incident...")
+		respData, err := util.BinocsAPI("/incidents/"+args[0], http.MethodGet, []byte{})
+		if err != nil {
+			handleErr(err)
+		}
+		var incident Incident
+		err = json.Unmarshal(respData, &incident)
+		if err != nil {
+			handleErr(err)
+		}
+		spin.Stop()
+
+		validate := func(val interface{}) error {
+			note := strings.TrimSpace(val.(string))
+			if len(note) == 0 {
+				return errors.New("note must not be empty")
+			} else if len([]rune(note)) > incidentNoteMaxLength {
+				return fmt.Errorf("note must not be longer than %d characters", incidentNoteMaxLength)
+			}
+			return nil
+		}
+		note := incidentUpdateFlagNote
+		if validate(note) != nil {
+			prompt := &survey.Input{
+				Message: "Incident note:",
+				Default: incident.IncidentNote,
+			}
+			err = survey.AskOne(prompt, &note, survey.WithValidator(validate))
+			if err != nil {
+				handleErr(err)
+			}
+		}
+
+		postData, err := json.Marshal(IncidentNoteUpdate{IncidentNote: strings.TrimSpace(note)})
+		if err != nil {
+			handleErr(err)
+		}
+		spin.Start()
+		spin.Suffix = colorFaint.Sprint(" updating incident...")
+		_, err = util.BinocsAPI("/incidents/"+incident.Ident, http.MethodPut, postData)
+		if err != nil {
+			handleErr(err)
+		}
+		spin.Stop()
+		fmt.Println("Incident " + incident.Ident + " updated successfully")
 	},
 }
 
+func requestsTableColumnDefinitions() []tableColumnDefinition {
+	return []tableColumnDefinition{
+		{
+			Header:    "CHECKED AT",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "FROM",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "RESPONSE",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+		{
+			Header:    "RESPONSE TIME",
+			Priority:  2,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "DNS LOOKUP",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "CONNECTION",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "TLS",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "WAITING",
+			Priority:  3,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+		{
+			Header:    "TRANSFER",
+			Priority:  4,
+			Alignment: tablewriter.ALIGN_RIGHT,
+		},
+	}
+}
+
+func makeRequestTableRow(request Request) []string {
+	var responseTime, timingsDNSLookup, timingsConnection, timingsTLS, timingsWait, timingsTransfer string
+	var timingsDNSLookupFloat, timingsConnectionFloat, timingsTLSFloat, timingsWaitFloat, timingsTransferFloat float64
+	if request.Timings.DSNLookup != "nil" {
+		timingsDNSLookup = fmt.Sprintf("%s s", request.Timings.DSNLookup)
+		timingsConnection = fmt.Sprintf("%s s", request.Timings.Connection)
+		timingsTLS = fmt.Sprintf("%s s", request.Timings.TLS)
+		timingsWait = fmt.Sprintf("%s s", request.Timings.Wait)
+		timingsTransfer = fmt.Sprintf("%s s", request.Timings.Transfer)
+		timingsDNSLookupFloat, _ = strconv.ParseFloat(request.Timings.DSNLookup, 32)
+		timingsConnectionFloat, _ = strconv.ParseFloat(request.Timings.Connection, 32)
+		timingsTLSFloat, _ = strconv.ParseFloat(request.Timings.TLS, 32)
+		timingsWaitFloat, _ = strconv.ParseFloat(request.Timings.Wait, 32)
+		timingsTransferFloat, _ = strconv.ParseFloat(request.Timings.Transfer, 32)
+		responseTime = fmt.Sprintf("%.3f s", timingsDNSLookupFloat+timingsConnectionFloat+timingsTLSFloat+timingsWaitFloat+timingsTransferFloat)
+	} else {
+		responseTime = "n/a"
+		timingsDNSLookup = "n/a"
+		timingsConnection = "n/a"
+		timingsTLS = "n/a"
+		timingsWait = "n/a"
+		timingsTransfer = "n/a"
+	}
+	return []string{request.Timestamp, regionAliases[request.Region], request.ResponseStatusCode, responseTime, colorFaint.Sprint(timingsDNSLookup),
+		colorFaint.Sprint(timingsConnection), colorFaint.Sprint(timingsTLS), colorFaint.Sprint(timingsWait), colorFaint.Sprint(timingsTransfer)}
+}
+
 func fetchIncidents(urlValues url.Values) ([]Incident, error) {
 	var incidents []Incident
 	respData, err := util.BinocsAPI("/incidents?"+urlValues.Encode(), http.MethodGet, []byte{})
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+const (
+	validPagerDutyRoutingKeyPattern = `^[a-zA-Z0-9]{32}$`
+	validOpsgenieAPIKeyPattern      = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+	validOpsgenieRegionPattern      = `^(us|eu)$`
+	opsgenieRegionUS                = "us"
+	opsgenieRegionEU                = "eu"
+	incidentAlertKeyPrefix          = "binocs-"
+)
+
+// OpsgenieConfig holds the settings of an Opsgenie channel besides the API key, which is the channel handle
+type OpsgenieConfig struct {
+	Region string `json:"region"`
+}
+
+// isOncallChannelType tells whether channels of the type trigger and resolve alerts of an incident management service
+func isOncallChannelType(channelType string) bool {
+	return channelType == channelTypePagerDuty || channelType == channelTypeOpsgenie
+}
+
+// incidentAlertKey is the PagerDuty dedup_key and the Opsgenie alias of alerts triggered for the incident;
+// the same key resolves the alert and attaches notes to it
+func incidentAlertKey(incidentIdent string) string {
+	return incidentAlertKeyPrefix + incidentIdent
+}
+
+// maskChannelKey hides all but the ends of the routing key or API key that PagerDuty and Opsgenie channels use as handle
+func maskChannelKey(key string) string {
+	if len(key) <= 8 {
+		return strings.Repeat("*", len(key))
+	}
+	return key[:4] + strings.Repeat("*", 4) + key[len(key)-4:]
+}
+
+// askOncallChannelKey prompts for the routing key or API key, unless key is valid
+func askOncallChannelKey(channelType, key string) (string, error) {
+	match, err := regexp.MatchString(validHandlePattern[channelType], key)
+	if err != nil || match {
+		return key, err
+	}
+	validate := func(val interface{}) error {
+		match, err := regexp.MatchString(validHandlePattern[channelType], strings.TrimSpace(val.(string)))
+		if err != nil {
+			return err
+		} else if !match {
+			return fmt.Errorf("invalid key format")
+		}
+		return nil
+	}
+	message := "Enter the Events API v2 integration key (routing key) of the PagerDuty service:"
+	if channelType == channelTypeOpsgenie {
+		message = "Enter the API key of the Opsgenie API integration:"
+	}
+	prompt := &survey.Password{
+		Message: message,
+	}
+	err = survey.AskOne(prompt, &key, survey.WithValidator(validate))
+	return strings.TrimSpace(key), err
+}
+
+// composeOpsgenieConfig applies the --region flag over the current configuration, if any; on update without
+// --region it returns nil, so that the API keeps the current settings, and the default region only applies on add
+func composeOpsgenieConfig(update bool, current *OpsgenieConfig, region string) (*OpsgenieConfig, error) {
+	if len(region) == 0 {
+		if update {
+			return nil, nil
+		}
+		return &OpsgenieConfig{Region: opsgenieRegionUS}, nil
+	}
+	region = strings.ToLower(region)
+	match, err := regexp.MatchString(validOpsgenieRegionPattern, region)
+	if err != nil || !match {
+		return nil, fmt.Errorf("Invalid region provided. Supported regions: %s, %s", opsgenieRegionUS, opsgenieRegionEU)
+	}
+	var config OpsgenieConfig
+	if current != nil {
+		config = *current
+	}
+	config.Region = region
+	return &config, nil
+}
@@ -0,0 +1,512 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	otelProtocolHTTP       = "http/json"
+	otelProtocolGRPC       = "grpc"
+	otelScopeName          = "binocs-cli"
+	otelIncidentEventName  = "binocs.incident"
+	otelMinInterval        = 10
+	otelRequestTimeout     = 30 * time.Second
+	otelSeverityInfo       = 9
+	otelSeverityWarn       = 13
+	otelMetricsPath        = "/v1/metrics"
+	otelLogsPath           = "/v1/logs"
+	otelDefaultEndpoint    = "http://localhost:4318"
+	otelDefaultGRPCAddress = "localhost:4317"
+	otelResourceAccountKey = "binocs.account"
+)
+
+// otelMetricNames maps exporter metric names onto OpenTelemetry metric names and units
+var otelMetricNames = map[string][2]string{
+	"binocs_check_status":           {"binocs.check.status", "1"},
+	"binocs_check_uptime_percent":   {"binocs.check.uptime", "%"},
+	"binocs_check_apdex":            {"binocs.check.apdex", "1"},
+	"binocs_check_mrt_seconds":      {"binocs.check.mrt", "s"},
+	"binocs_check_last_status_code": {"binocs.check.last_status_code", "1"},
+	"binocs_check_incident_open":    {"binocs.check.incident_open", "1"},
+	"binocs_credit_balance":         {"binocs.credit_balance", "1"},
+}
+
+// otelGRPCMethods are the OTLP/gRPC counterparts of the OTLP/HTTP paths
+var otelGRPCMethods = map[string]string{
+	otelMetricsPath: "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export",
+	otelLogsPath:    "/opentelemetry.proto.collector.logs.v1.LogsService/Export",
+}
+
+// otelPayload is an OTLP export request, encoded as JSON for OTLP/HTTP, and as protobuf for OTLP/gRPC
+type otelPayload interface {
+	marshalProto() []byte
+}
+
+// OTLP/JSON payloads, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otelAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+}
+
+type otelKeyValue struct {
+	Key   string       `json:"key"`
+	Value otelAnyValue `json:"value"`
+}
+
+type otelResource struct {
+	Attributes []otelKeyValue `json:"attributes"`
+}
+
+type otelScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otelNumberDataPoint struct {
+	TimeUnixNano string  `json:"timeUnixNano"`
+	AsDouble     float64 `json:"asDouble"`
+}
+
+type otelGauge struct {
+	DataPoints []otelNumberDataPoint `json:"dataPoints"`
+}
+
+type otelMetric struct {
+	Name  string    `json:"name"`
+	Unit  string    `json:"unit"`
+	Gauge otelGauge `json:"gauge"`
+}
+
+type otelScopeMetrics struct {
+	Scope   otelScope    `json:"scope"`
+	Metrics []otelMetric `json:"metrics"`
+}
+
+type otelResourceMetrics struct {
+	Resource     otelResource       `json:"resource"`
+	ScopeMetrics []otelScopeMetrics `json:"scopeMetrics"`
+}
+
+type otelMetricsRequest struct {
+	ResourceMetrics []otelResourceMetrics `json:"resourceMetrics"`
+}
+
+type otelLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otelAnyValue   `json:"body"`
+	Attributes           []otelKeyValue `json:"attributes"`
+}
+
+type otelScopeLogs struct {
+	Scope      otelScope       `json:"scope"`
+	LogRecords []otelLogRecord `json:"logRecords"`
+}
+
+type otelResourceLogs struct {
+	Resource  otelResource    `json:"resource"`
+	ScopeLogs []otelScopeLogs `json:"scopeLogs"`
+}
+
+type otelLogsRequest struct {
+	ResourceLogs []otelResourceLogs `json:"resourceLogs"`
+}
+
+// `otel push` flags
+var (
+	otelPushFlagEndpoint    string
+	otelPushFlagProtocol    string
+	otelPushFlagHeaders     []string
+	otelPushFlagPeriod      string
+	otelPushFlagInterval    int
+	otelPushFlagConcurrency int
+	otelPushFlagOnce        bool
+)
+
+func init() {
+	rootCmd.AddCommand(otelCmd)
+	otelCmd.AddCommand(otelPushCmd)
+
+	otelPushCmd.Flags().StringVar(&otelPushFlagEndpoint, "endpoint", "", "OTLP endpoint of your collector; default "+otelDefaultGRPCAddress+" for grpc, "+otelDefaultEndpoint+" for http/json")
+	otelPushCmd.Flags().StringVar(&otelPushFlagProtocol, "protocol", otelProtocolGRPC, "OTLP protocol, \"grpc\" or \"http/json\"")
+	otelPushCmd.Flags().StringSliceVar(&otelPushFlagHeaders, "header", []string{}, "HTTP header or gRPC metadata to send with every export, e.g. \"Authorization=Bearer xyz\"; can be repeated")
+	otelPushCmd.Flags().StringVarP(&otelPushFlagPeriod, "period", "p", "day", "period of uptime, apdex and mrt values")
+	otelPushCmd.Flags().IntVar(&otelPushFlagInterval, "interval", 60, "how often to push, in seconds; with --once, how far back to look for incidents")
+	otelPushCmd.Flags().IntVar(&otelPushFlagConcurrency, "concurrency", 4, "how many API requests to make at once while reading metrics")
+	otelPushCmd.Flags().BoolVar(&otelPushFlagOnce, "once", false, "push once and exit, e.g. when running from cron")
+	otelPushCmd.Flags().SortFlags = false
+}
+
+var otelCmd = &cobra.Command{
+	Use:   "otel",
+	Short: "Export to OpenTelemetry",
+	Long: `
+Export check metrics and incidents to OpenTelemetry.
+`,
+	DisableAutoGenTag: true,
+}
+
+var otelPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Push metrics and incidents to an OTLP collector",
+	Long: `
+Periodically push check metrics as OTLP gauges, and incidents as OTLP log events, to an OpenTelemetry collector.
+
+Metrics are the same as those of "binocs exporter", named binocs.check.status, binocs.check.uptime, binocs.check.apdex,
+binocs.check.mrt, binocs.check.last_status_code, binocs.check.incident_open and binocs.credit_balance.
+Each check and region is a separate resource with binocs.check.ident, binocs.check.name, binocs.check.protocol
+and binocs.region attributes.
+
+Incidents opened or resolved since the previous push are sent as "binocs.incident" events.
+
+OTLP over gRPC is used by default, usually on port 4317 of the collector; endpoints without https:// are plaintext.
+Use --protocol http/json for OTLP over HTTP with JSON encoding, usually on port 4318.
+`,
+	Example: `  binocs otel push --endpoint localhost:4317
+  binocs otel push --protocol http/json --endpoint http://localhost:4318
+  binocs otel push --endpoint https://otlp.example.com --header "Authorization=Bearer xyz" --once --interval 300`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+		spin.Disable()
+
+		if otelPushFlagProtocol != otelProtocolGRPC && otelPushFlagProtocol != otelProtocolHTTP {
+			handleErr(fmt.Errorf("Invalid protocol provided, use \"%s\" or \"%s\"", otelProtocolGRPC, otelProtocolHTTP))
+		}
+		match, err := regexp.MatchString(validPeriodPattern, otelPushFlagPeriod)
+		if err != nil || !match {
+			handleErr(fmt.Errorf("Invalid period provided. Supported periods: hour, day, week, month"))
+		}
+		if otelPushFlagInterval < otelMinInterval {
+			handleErr(fmt.Errorf("Interval must be at least %d seconds", otelMinInterval))
+		}
+		if otelPushFlagConcurrency < 1 || otelPushFlagConcurrency > exporterMaxConcurrency {
+			handleErr(fmt.Errorf("Concurrency must be between 1 and %d", exporterMaxConcurrency))
+		}
+		pusher, err := newOtelPusher(otelPushFlagProtocol, otelPushFlagEndpoint, otelPushFlagHeaders)
+		if err != nil {
+			handleErr(err)
+		}
+
+		interval := time.Duration(otelPushFlagInterval) * time.Second
+		since := time.Now().Add(-interval)
+		if otelPushFlagOnce {
+			err = pusher.push(since)
+			if err != nil {
+				handleErr(err)
+			}
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		log.Printf("pushing to %s every %v", pusher.endpoint, interval)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			pushed := time.Now()
+			err = pusher.push(since)
+			if err != nil {
+				log.Printf("cannot push: %v", err)
+			} else {
+				since = pushed
+			}
+			select {
+			case <-ctx.Done():
+				log.Printf("otel push stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+type otelPusher struct {
+	protocol string
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+func newOtelPusher(protocol, endpoint string, headers []string) (*otelPusher, error) {
+	if len(endpoint) == 0 {
+		endpoint = otelDefaultEndpoint
+		if protocol == otelProtocolGRPC {
+			endpoint = otelDefaultGRPCAddress
+		}
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || len(u.Host) == 0 {
+		return nil, fmt.Errorf("Invalid endpoint provided: %s", endpoint)
+	}
+	p := &otelPusher{
+		protocol: protocol,
+		endpoint: strings.TrimRight(u.String(), "/"),
+		headers:  map[string]string{},
+		client:   &http.Client{Timeout: otelRequestTimeout},
+	}
+	if protocol == otelProtocolGRPC {
+		p.client = newOtelGRPCClient()
+	}
+	for _, h := range headers {
+		kv := strings.SplitN(h, "=", 2)
+		if len(kv) != 2 || len(strings.TrimSpace(kv[0])) == 0 {
+			return nil, fmt.Errorf("Invalid header provided: %s; use e.g. \"Authorization=Bearer xyz\"", h)
+		}
+		p.headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return p, nil
+}
+
+// push sends current metrics, and incidents opened or resolved after since
+func (p *otelPusher) push(since time.Time) error {
+	now := time.Now()
+	samples, err := collectCheckSamples(otelPushFlagPeriod, otelPushFlagConcurrency)
+	if err != nil {
+		return err
+	}
+	err = p.post(otelMetricsPath, composeOtelMetrics(samples, now))
+	if err != nil {
+		return err
+	}
+
+	urlValues := url.Values{}
+	urlValues.Set("from", since.UTC().Format(time.RFC3339))
+	urlValues.Set("to", now.UTC().Format(time.RFC3339))
+	incidents, err := fetchIncidents(urlValues)
+	if err != nil {
+		return err
+	}
+	logs := composeOtelIncidentLogs(incidents, since, now)
+	if len(logs.ResourceLogs) > 0 {
+		err = p.post(otelLogsPath, logs)
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("pushed %d metric value(s) and %d incident event(s)", len(samples), countOtelLogRecords(logs))
+	return nil
+}
+
+func (p *otelPusher) post(path string, payload otelPayload) error {
+	if p.protocol == otelProtocolGRPC {
+		return p.postGRPC(otelGRPCMethods[path], payload.marshalProto())
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "binocs-cli/"+BinocsVersion)
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector responded to %s with %s: %s", path, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// postGRPC makes a unary gRPC call; the status of the call comes in the trailers,
+// or in the headers when the collector fails it right away
+func (p *otelPusher) postGRPC(method string, message []byte) error {
+	body := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(message)))
+	body = append(body, message...)
+	req, err := http.NewRequest(http.MethodPost, p.endpoint+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	req.Header.Set("User-Agent", "binocs-cli/"+BinocsVersion)
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("collector responded to %s with %s", method, resp.Status)
+	}
+	status, statusMessage := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if len(status) == 0 {
+		status, statusMessage = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if len(status) == 0 {
+		return fmt.Errorf("collector responded to %s without a gRPC status", method)
+	}
+	if status != "0" {
+		if unescaped, err := url.PathUnescape(statusMessage); err == nil {
+			statusMessage = unescaped
+		}
+		return fmt.Errorf("collector responded to %s with gRPC status %s: %s", method, status, statusMessage)
+	}
+	return nil
+}
+
+func otelString(key, value string) otelKeyValue {
+	return otelKeyValue{Key: key, Value: otelAnyValue{StringValue: &value}}
+}
+
+func otelCheckResource(c *Check, region string) otelResource {
+	attributes := []otelKeyValue{otelString("service.name", "binocs")}
+	if c == nil {
+		return otelResource{Attributes: append(attributes, otelString(otelResourceAccountKey, "true"))}
+	}
+	return otelResource{Attributes: append(attributes,
+		otelString("binocs.check.ident", c.Ident),
+		otelString("binocs.check.name", c.Name),
+		otelString("binocs.check.protocol", c.Protocol),
+		otelString("binocs.region", region),
+	)}
+}
+
+// composeOtelMetrics groups samples into a resource per check and region
+func composeOtelMetrics(samples []exporterSample, now time.Time) otelMetricsRequest {
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+	resources := map[string]*otelResourceMetrics{}
+	var keys []string
+	for _, s := range samples {
+		name, ok := otelMetricNames[s.name]
+		if !ok {
+			continue
+		}
+		key := s.labels()
+		rm, ok := resources[key]
+		if !ok {
+			rm = &otelResourceMetrics{
+				Resource:     otelCheckResource(s.check, s.region),
+				ScopeMetrics: []otelScopeMetrics{{Scope: otelScope{Name: otelScopeName, Version: BinocsVersion}}},
+			}
+			resources[key] = rm
+			keys = append(keys, key)
+		}
+		rm.ScopeMetrics[0].Metrics = append(rm.ScopeMetrics[0].Metrics, otelMetric{
+			Name:  name[0],
+			Unit:  name[1],
+			Gauge: otelGauge{DataPoints: []otelNumberDataPoint{{TimeUnixNano: timestamp, AsDouble: s.value}}},
+		})
+	}
+	sort.Strings(keys)
+	request := otelMetricsRequest{ResourceMetrics: []otelResourceMetrics{}}
+	for _, key := range keys {
+		request.ResourceMetrics = append(request.ResourceMetrics, *resources[key])
+	}
+	return request
+}
+
+// composeOtelIncidentLogs makes an event of every incident opened or resolved within since and now
+func composeOtelIncidentLogs(incidents []Incident, since, now time.Time) otelLogsRequest {
+	request := otelLogsRequest{ResourceLogs: []otelResourceLogs{}}
+	resources := map[string]*otelResourceLogs{}
+	var keys []string
+	for _, v := range incidents {
+		var records []otelLogRecord
+		opened, err := time.Parse(statusHistoryTimeLayout, v.Opened)
+		if err == nil && !opened.Before(since) && opened.Before(now) {
+			records = append(records, composeOtelIncidentRecord(v, "opened", opened, now))
+		}
+		closed, err := time.Parse(statusHistoryTimeLayout, v.Closed)
+		if err == nil && v.IncidentState != incidentStateOpen && !closed.Before(since) && closed.Before(now) {
+			records = append(records, composeOtelIncidentRecord(v, "resolved", closed, now))
+		}
+		if len(records) == 0 {
+			continue
+		}
+		rl, ok := resources[v.CheckIdent]
+		if !ok {
+			c := &Check{Ident: v.CheckIdent, Name: v.CheckName, Protocol: v.CheckProtocol}
+			rl = &otelResourceLogs{
+				Resource:  otelCheckResource(c, exporterRegionAll),
+				ScopeLogs: []otelScopeLogs{{Scope: otelScope{Name: otelScopeName, Version: BinocsVersion}}},
+			}
+			resources[v.CheckIdent] = rl
+			keys = append(keys, v.CheckIdent)
+		}
+		rl.ScopeLogs[0].LogRecords = append(rl.ScopeLogs[0].LogRecords, records...)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		request.ResourceLogs = append(request.ResourceLogs, *resources[key])
+	}
+	return request
+}
+
+func composeOtelIncidentRecord(v Incident, action string, t, now time.Time) otelLogRecord {
+	severity, severityText := otelSeverityWarn, "WARN"
+	if action == "resolved" {
+		severity, severityText = otelSeverityInfo, "INFO"
+	}
+	body := fmt.Sprintf("Incident %s of check %s %s", v.Ident, v.CheckIdent, action)
+	attributes := []otelKeyValue{
+		otelString("event.name", otelIncidentEventName),
+		otelString("binocs.incident.ident", v.Ident),
+		otelString("binocs.incident.state", v.IncidentState),
+		otelString("binocs.incident.action", action),
+	}
+	if len(v.Duration) > 0 {
+		attributes = append(attributes, otelString("binocs.incident.duration", v.Duration))
+	}
+	if len(v.IncidentNote) > 0 {
+		attributes = append(attributes, otelString("binocs.incident.note", v.IncidentNote))
+	}
+	return otelLogRecord{
+		TimeUnixNano:         strconv.FormatInt(t.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(now.UnixNano(), 10),
+		SeverityNumber:       severity,
+		SeverityText:         severityText,
+		Body:                 otelAnyValue{StringValue: &body},
+		Attributes:           attributes,
+	}
+}
+
+func countOtelLogRecords(logs otelLogsRequest) int {
+	var n int
+	for _, rl := range logs.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			n += len(sl.LogRecords)
+		}
+	}
+	return n
+}
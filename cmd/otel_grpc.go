@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"net/http"
+)
+
+// newOtelGRPCClient speaks HTTP/2 over TLS for https:// endpoints, and HTTP/2 with prior knowledge (h2c) otherwise
+func newOtelGRPCClient() *http.Client {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Protocols = protocols
+	return &http.Client{Transport: transport, Timeout: otelRequestTimeout}
+}
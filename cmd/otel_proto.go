@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+// OTLP/protobuf encoding of the OTLP/JSON payloads, used by OTLP over gRPC;
+// field numbers follow https://github.com/open-telemetry/opentelemetry-proto
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+)
+
+func protoAppendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func protoAppendTag(b []byte, field int, wireType int) []byte {
+	return protoAppendVarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func protoAppendBytes(b []byte, field int, value []byte) []byte {
+	b = protoAppendTag(b, field, protoWireBytes)
+	b = protoAppendVarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+func protoAppendString(b []byte, field int, value string) []byte {
+	return protoAppendBytes(b, field, []byte(value))
+}
+
+func protoAppendFixed64(b []byte, field int, value uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], value)
+	b = protoAppendTag(b, field, protoWireFixed64)
+	return append(b, buf[:]...)
+}
+
+// protoAppendUnixNano encodes OTLP/JSON timestamps, which are decimal strings, as fixed64
+func protoAppendUnixNano(b []byte, field int, value string) []byte {
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return b
+	}
+	return protoAppendFixed64(b, field, n)
+}
+
+func (v otelAnyValue) marshalProto() []byte {
+	var b []byte
+	if v.StringValue != nil {
+		b = protoAppendString(b, 1, *v.StringValue)
+	}
+	return b
+}
+
+func (kv otelKeyValue) marshalProto() []byte {
+	b := protoAppendString(nil, 1, kv.Key)
+	return protoAppendBytes(b, 2, kv.Value.marshalProto())
+}
+
+func protoAppendAttributes(b []byte, field int, attributes []otelKeyValue) []byte {
+	for _, kv := range attributes {
+		b = protoAppendBytes(b, field, kv.marshalProto())
+	}
+	return b
+}
+
+func (r otelResource) marshalProto() []byte {
+	return protoAppendAttributes(nil, 1, r.Attributes)
+}
+
+func (s otelScope) marshalProto() []byte {
+	b := protoAppendString(nil, 1, s.Name)
+	return protoAppendString(b, 2, s.Version)
+}
+
+func (dp otelNumberDataPoint) marshalProto() []byte {
+	b := protoAppendUnixNano(nil, 3, dp.TimeUnixNano)
+	// as_double is part of a oneof, so a zero value is sent, too
+	return protoAppendFixed64(b, 4, math.Float64bits(dp.AsDouble))
+}
+
+func (m otelMetric) marshalProto() []byte {
+	b := protoAppendString(nil, 1, m.Name)
+	b = protoAppendString(b, 3, m.Unit)
+	var gauge []byte
+	for _, dp := range m.Gauge.DataPoints {
+		gauge = protoAppendBytes(gauge, 1, dp.marshalProto())
+	}
+	return protoAppendBytes(b, 5, gauge)
+}
+
+func (sm otelScopeMetrics) marshalProto() []byte {
+	b := protoAppendBytes(nil, 1, sm.Scope.marshalProto())
+	for _, m := range sm.Metrics {
+		b = protoAppendBytes(b, 2, m.marshalProto())
+	}
+	return b
+}
+
+func (rm otelResourceMetrics) marshalProto() []byte {
+	b := protoAppendBytes(nil, 1, rm.Resource.marshalProto())
+	for _, sm := range rm.ScopeMetrics {
+		b = protoAppendBytes(b, 2, sm.marshalProto())
+	}
+	return b
+}
+
+// marshalProto encodes an ExportMetricsServiceRequest
+func (r otelMetricsRequest) marshalProto() []byte {
+	var b []byte
+	for _, rm := range r.ResourceMetrics {
+		b = protoAppendBytes(b, 1, rm.marshalProto())
+	}
+	return b
+}
+
+func (lr otelLogRecord) marshalProto() []byte {
+	b := protoAppendUnixNano(nil, 1, lr.TimeUnixNano)
+	b = protoAppendTag(b, 2, protoWireVarint)
+	b = protoAppendVarint(b, uint64(lr.SeverityNumber))
+	b = protoAppendString(b, 3, lr.SeverityText)
+	b = protoAppendBytes(b, 5, lr.Body.marshalProto())
+	b = protoAppendAttributes(b, 6, lr.Attributes)
+	return protoAppendUnixNano(b, 11, lr.ObservedTimeUnixNano)
+}
+
+func (sl otelScopeLogs) marshalProto() []byte {
+	b := protoAppendBytes(nil, 1, sl.Scope.marshalProto())
+	for _, lr := range sl.LogRecords {
+		b = protoAppendBytes(b, 2, lr.marshalProto())
+	}
+	return b
+}
+
+func (rl otelResourceLogs) marshalProto() []byte {
+	b := protoAppendBytes(nil, 1, rl.Resource.marshalProto())
+	for _, sl := range rl.ScopeLogs {
+		b = protoAppendBytes(b, 2, sl.marshalProto())
+	}
+	return b
+}
+
+// marshalProto encodes an ExportLogsServiceRequest
+func (r otelLogsRequest) marshalProto() []byte {
+	var b []byte
+	for _, rl := range r.ResourceLogs {
+		b = protoAppendBytes(b, 1, rl.marshalProto())
+	}
+	return b
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// protoField is a decoded protobuf field; value holds varint and fixed64 values, data length-delimited ones
+type protoField struct {
+	number   int
+	wireType int
+	value    uint64
+	data     []byte
+}
+
+func decodeProtoVarint(t *testing.T, b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	t.Fatalf("truncated varint in % x", b)
+	return 0, 0
+}
+
+func decodeProto(t *testing.T, b []byte) []protoField {
+	var fields []protoField
+	for len(b) > 0 {
+		tag, n := decodeProtoVarint(t, b)
+		b = b[n:]
+		f := protoField{number: int(tag >> 3), wireType: int(tag & 7)}
+		switch f.wireType {
+		case protoWireVarint:
+			f.value, n = decodeProtoVarint(t, b)
+			b = b[n:]
+		case protoWireFixed64:
+			if len(b) < 8 {
+				t.Fatalf("truncated fixed64 field %d", f.number)
+			}
+			f.value, b = binary.LittleEndian.Uint64(b), b[8:]
+		case protoWireBytes:
+			l, n := decodeProtoVarint(t, b)
+			b = b[n:]
+			if uint64(len(b)) < l {
+				t.Fatalf("truncated length-delimited field %d", f.number)
+			}
+			f.data, b = b[:l], b[l:]
+		default:
+			t.Fatalf("unexpected wire type %d of field %d", f.wireType, f.number)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// protoLookup descends into the first field of each number of the path, and returns the last one
+func protoLookup(t *testing.T, b []byte, path []int) protoField {
+	var found protoField
+	for i, number := range path {
+		var ok bool
+		for _, f := range decodeProto(t, b) {
+			if f.number == number {
+				found, ok = f, true
+				break
+			}
+		}
+		if !ok {
+			t.Fatalf("field %v not found", path[:i+1])
+		}
+		b = found.data
+	}
+	return found
+}
+
+func TestOtelMarshalProto(t *testing.T) {
+	str := func(s string) *string { return &s }
+	scope := otelScope{Name: otelScopeName, Version: "v1.2.3"}
+	resource := otelResource{Attributes: []otelKeyValue{otelString("service.name", "binocs"), otelString("binocs.check.ident", "abcdef1")}}
+	metrics := otelMetricsRequest{ResourceMetrics: []otelResourceMetrics{{
+		Resource: resource,
+		ScopeMetrics: []otelScopeMetrics{{Scope: scope, Metrics: []otelMetric{{
+			Name:  "binocs.check.status",
+			Unit:  "1",
+			Gauge: otelGauge{DataPoints: []otelNumberDataPoint{{TimeUnixNano: "1700000000000000001", AsDouble: 0}}},
+		}, {
+			Name:  "binocs.check.uptime",
+			Unit:  "%",
+			Gauge: otelGauge{DataPoints: []otelNumberDataPoint{{TimeUnixNano: "1700000000000000001", AsDouble: 99.5}}},
+		}}}},
+	}}}.marshalProto()
+	logs := otelLogsRequest{ResourceLogs: []otelResourceLogs{{
+		Resource: resource,
+		ScopeLogs: []otelScopeLogs{{Scope: scope, LogRecords: []otelLogRecord{{
+			TimeUnixNano:         "1700000000000000002",
+			ObservedTimeUnixNano: "1700000000000000003",
+			SeverityNumber:       otelSeverityWarn,
+			SeverityText:         "WARN",
+			Body:                 otelAnyValue{StringValue: str("Incident q9w8e7 of check abcdef1 opened")},
+			Attributes:           []otelKeyValue{otelString("event.name", otelIncidentEventName)},
+		}}}},
+	}}}.marshalProto()
+
+	tests := []struct {
+		name     string
+		payload  []byte
+		path     []int
+		wireType int
+		value    uint64
+		data     string
+	}{
+		// ExportMetricsServiceRequest.resource_metrics.resource.attributes
+		{"metrics resource attribute key", metrics, []int{1, 1, 1, 1}, protoWireBytes, 0, "service.name"},
+		{"metrics resource attribute value", metrics, []int{1, 1, 1, 2, 1}, protoWireBytes, 0, "binocs"},
+		// ResourceMetrics.scope_metrics.scope
+		{"metrics scope name", metrics, []int{1, 2, 1, 1}, protoWireBytes, 0, otelScopeName},
+		{"metrics scope version", metrics, []int{1, 2, 1, 2}, protoWireBytes, 0, "v1.2.3"},
+		// ScopeMetrics.metrics, Metric.gauge.data_points
+		{"metric name", metrics, []int{1, 2, 2, 1}, protoWireBytes, 0, "binocs.check.status"},
+		{"metric unit", metrics, []int{1, 2, 2, 3}, protoWireBytes, 0, "1"},
+		{"data point time", metrics, []int{1, 2, 2, 5, 1, 3}, protoWireFixed64, 1700000000000000001, ""},
+		{"data point zero double", metrics, []int{1, 2, 2, 5, 1, 4}, protoWireFixed64, math.Float64bits(0), ""},
+		// ExportLogsServiceRequest.resource_logs
+		{"logs resource attribute key", logs, []int{1, 1, 1, 1}, protoWireBytes, 0, "service.name"},
+		{"logs scope name", logs, []int{1, 2, 1, 1}, protoWireBytes, 0, otelScopeName},
+		// ScopeLogs.log_records
+		{"log time", logs, []int{1, 2, 2, 1}, protoWireFixed64, 1700000000000000002, ""},
+		{"log severity number", logs, []int{1, 2, 2, 2}, protoWireVarint, otelSeverityWarn, ""},
+		{"log severity text", logs, []int{1, 2, 2, 3}, protoWireBytes, 0, "WARN"},
+		{"log body", logs, []int{1, 2, 2, 5, 1}, protoWireBytes, 0, "Incident q9w8e7 of check abcdef1 opened"},
+		{"log attribute key", logs, []int{1, 2, 2, 6, 1}, protoWireBytes, 0, "event.name"},
+		{"log attribute value", logs, []int{1, 2, 2, 6, 2, 1}, protoWireBytes, 0, otelIncidentEventName},
+		{"log observed time", logs, []int{1, 2, 2, 11}, protoWireFixed64, 1700000000000000003, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := protoLookup(t, tt.payload, tt.path)
+			if f.wireType != tt.wireType {
+				t.Fatalf("wire type = %d, want %d", f.wireType, tt.wireType)
+			}
+			if tt.wireType == protoWireBytes && string(f.data) != tt.data {
+				t.Fatalf("value = %q, want %q", f.data, tt.data)
+			}
+			if tt.wireType != protoWireBytes && f.value != tt.value {
+				t.Fatalf("value = %d, want %d", f.value, tt.value)
+			}
+		})
+	}
+
+	t.Run("second metric and its double", func(t *testing.T) {
+		var found []protoField
+		for _, f := range decodeProto(t, protoLookup(t, metrics, []int{1, 2}).data) {
+			if f.number == 2 {
+				found = append(found, f)
+			}
+		}
+		if len(found) != 2 {
+			t.Fatalf("metrics = %d, want 2", len(found))
+		}
+		name := protoLookup(t, found[1].data, []int{1})
+		value := protoLookup(t, found[1].data, []int{5, 1, 4})
+		if string(name.data) != "binocs.check.uptime" || math.Float64frombits(value.value) != 99.5 {
+			t.Fatalf("second metric = %q %v, want binocs.check.uptime 99.5", name.data, math.Float64frombits(value.value))
+		}
+	})
+}
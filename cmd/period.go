@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	comparePrevious       = "previous"
+	validSincePattern     = `^([0-9]+(w|d|h|m))+$`
+	maxRangeDataPoints    = 120
+	minRangeDuration      = 5 * time.Minute
+	rangeTimeLabelLayout  = "15:04"
+	rangeDateLabelLayout  = "Mon 02"
+	rangeTitleDateLayout  = "Jan 02"
+	rangeTitleTimeLayout  = "Jan 02 15:04"
+	rangeTitleShortLayout = "15:04"
+)
+
+// rangeSteps are the aggregation steps a custom time range can be split into, from the finest
+var rangeSteps = []time.Duration{
+	1 * time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	1 * time.Hour,
+	2 * time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+}
+
+var supportedTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// timeRange is the time span metrics, charts and incidents are displayed for.
+// Period is set for one of the supportedPeriods ending now, From and To for a custom range.
+// Points overrides the number of data points, so that a compared range lines up with the original one.
+type timeRange struct {
+	Period string
+	Since  string
+	From   time.Time
+	To     time.Time
+	Points int
+}
+
+// parseTimeRange validates the --period, --from, --to and --since flag values; fallback is the period used when none
+// of them is set. --from and --to are read in the user's timezone unless they carry an explicit offset
+func parseTimeRange(period, from, to, since, fallback string, tz *time.Location) (timeRange, error) {
+	var tr timeRange
+	now := time.Now().In(tz)
+
+	if len(period) > 0 && (len(from) > 0 || len(to) > 0 || len(since) > 0) {
+		return tr, fmt.Errorf("Cannot use --period together with --from, --to or --since")
+	}
+
+	if len(since) > 0 {
+		if len(from) > 0 || len(to) > 0 {
+			return tr, fmt.Errorf("Cannot use --since together with --from or --to")
+		}
+		d, err := parseSince(since)
+		if err != nil {
+			return tr, err
+		}
+		tr.Since = strings.ToLower(since)
+		tr.From = now.Add(-d)
+		tr.To = now
+		return tr, tr.validate()
+	}
+
+	if len(from) > 0 {
+		var err error
+		tr.From, err = parseTimeRangeValue(from, tz)
+		if err != nil {
+			return tr, err
+		}
+		tr.To = now
+		if len(to) > 0 {
+			tr.To, err = parseTimeRangeValue(to, tz)
+			if err != nil {
+				return tr, err
+			}
+		}
+		if tr.To.After(now) {
+			tr.To = now
+		}
+		return tr, tr.validate()
+	}
+
+	if len(to) > 0 {
+		return tr, fmt.Errorf("Cannot use --to without --from")
+	}
+
+	if len(period) == 0 {
+		period = fallback
+	}
+	if len(period) > 0 {
+		match, err := regexp.MatchString(validPeriodPattern, period)
+		if err != nil || !match {
+			return tr, fmt.Errorf("Invalid period provided. Supported periods: hour, day, week, month")
+		}
+		tr.Period = period
+		tr.From = now.Add(-supportedPeriods[period])
+		tr.To = now
+	}
+	return tr, nil
+}
+
+func parseTimeRangeValue(value string, tz *time.Location) (time.Time, error) {
+	for _, layout := range supportedTimeLayouts {
+		t, err := time.ParseInLocation(layout, value, tz)
+		if err == nil {
+			return t.In(tz), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid time provided: %s; use e.g. 2006-01-02T15:04 or 2006-01-02", value)
+}
+
+// parseSince reads durations like 90m, 3h, 2d or 1w2d
+func parseSince(value string) (time.Duration, error) {
+	value = strings.ToLower(value)
+	match, err := regexp.MatchString(validSincePattern, value)
+	if err != nil || !match {
+		return 0, fmt.Errorf("Invalid --since value provided: %s; use e.g. 90m, 3h, 2d or 1w", value)
+	}
+	var d time.Duration
+	rx := regexp.MustCompile(`([0-9]+)(w|d|h|m)`)
+	for _, m := range rx.FindAllStringSubmatch(value, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "w":
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case "d":
+			d += time.Duration(n) * 24 * time.Hour
+		case "h":
+			d += time.Duration(n) * time.Hour
+		case "m":
+			d += time.Duration(n) * time.Minute
+		}
+	}
+	return d, nil
+}
+
+func (tr timeRange) validate() error {
+	if !tr.From.Before(tr.To) {
+		return fmt.Errorf("Invalid time range provided: --from must be before --to and in the past")
+	}
+	if tr.To.Sub(tr.From) < minRangeDuration {
+		return fmt.Errorf("Invalid time range provided: must span at least %d minutes", int(minRangeDuration.Minutes()))
+	}
+	maxRange := rangeSteps[len(rangeSteps)-1] * maxRangeDataPoints
+	if tr.To.Sub(tr.From) > maxRange {
+		return fmt.Errorf("Invalid time range provided: must not span more than %d days", int(maxRange.Hours()/24))
+	}
+	return nil
+}
+
+// parseCompareRange returns the range to compare tr against and its title; compare is either
+// "previous", the range of the same length right before tr, or a duration to shift tr back by, e.g. 1w
+func parseCompareRange(tr timeRange, compare string) (timeRange, string, error) {
+	if strings.ToLower(compare) == comparePrevious {
+		return tr.shift(tr.To.Sub(tr.From)), "PREVIOUS", nil
+	}
+	d, err := parseSince(compare)
+	if err != nil || d == 0 {
+		return timeRange{}, "", fmt.Errorf("Invalid --compare value provided: %s; use \"previous\" or a duration, e.g. 1d or 1w", compare)
+	}
+	return tr.shift(d), strings.ToUpper(compare) + " AGO", nil
+}
+
+// shift moves the range d back in time, keeping its data points
+func (tr timeRange) shift(d time.Duration) timeRange {
+	return timeRange{
+		From:   tr.From.Add(-d),
+		To:     tr.To.Add(-d),
+		Points: tr.dataPoints(),
+	}
+}
+
+// isCustom is true for ranges given by --from, --to or --since
+func (tr timeRange) isCustom() bool {
+	return tr.Period == "" && !tr.From.IsZero()
+}
+
+// step is the aggregation step of a single data point
+func (tr timeRange) step() time.Duration {
+	if !tr.isCustom() {
+		return supportedPeriods[tr.Period] / time.Duration(aggregateMetricsDataPoints[tr.Period])
+	}
+	d := tr.To.Sub(tr.From)
+	if tr.Points > 0 {
+		return d / time.Duration(tr.Points)
+	}
+	for _, s := range rangeSteps {
+		if int(math.Ceil(float64(d)/float64(s))) <= maxRangeDataPoints {
+			return s
+		}
+	}
+	return rangeSteps[len(rangeSteps)-1]
+}
+
+// dataPoints is the number of aggregated values (chart columns) the range is split into
+func (tr timeRange) dataPoints() int {
+	if !tr.isCustom() {
+		return aggregateMetricsDataPoints[tr.Period]
+	}
+	if tr.Points > 0 {
+		return tr.Points
+	}
+	return int(math.Ceil(float64(tr.To.Sub(tr.From)) / float64(tr.step())))
+}
+
+// setURLValues sets API query parameters; aggregated endpoints get the number of data points, too
+func (tr timeRange) setURLValues(v *url.Values, aggregated bool) {
+	if !tr.isCustom() {
+		if len(tr.Period) > 0 {
+			v.Set("period", tr.Period)
+		}
+		return
+	}
+	v.Del("period")
+	v.Set("from", tr.From.UTC().Format(time.RFC3339))
+	v.Set("to", tr.To.UTC().Format(time.RFC3339))
+	if aggregated {
+		v.Set("points", strconv.Itoa(tr.dataPoints()))
+	}
+}
+
+// title is used in table headers and chart titles
+func (tr timeRange) title() string {
+	switch {
+	case tr.Period == periodHour:
+		return "1 HOUR"
+	case tr.Period == periodDay:
+		return "1 DAY"
+	case tr.Period == periodWeek:
+		return "1 WEEK"
+	case tr.Period == periodMonth:
+		return "1 MONTH"
+	case len(tr.Since) > 0:
+		return "LAST " + strings.ToUpper(tr.Since)
+	case tr.isCustom() && tr.step() >= 24*time.Hour:
+		return tr.From.Format(rangeTitleDateLayout) + " - " + tr.To.Format(rangeTitleDateLayout)
+	case tr.isCustom() && tr.From.YearDay() == tr.To.YearDay() && tr.From.Year() == tr.To.Year():
+		return tr.From.Format(rangeTitleTimeLayout) + " - " + tr.To.Format(rangeTitleShortLayout)
+	case tr.isCustom():
+		return tr.From.Format(rangeTitleTimeLayout) + " - " + tr.To.Format(rangeTitleTimeLayout)
+	}
+	return ""
+}
+
+// drawRangeTimeline labels chart columns of a custom range, starting from its left edge;
+// dates are put on the second line whenever the day (or month, for daily steps) changes
+func drawRangeTimeline(tr timeRange, tz *time.Location) [2]string {
+	dataPoints := tr.dataPoints()
+	step := tr.step()
+	lines := [2][]rune{[]rune(strings.Repeat(" ", dataPoints)), []rune(strings.Repeat(" ", dataPoints))}
+
+	labelLayout, dateLayout := rangeTimeLabelLayout, rangeDateLabelLayout
+	if step >= 24*time.Hour {
+		labelLayout, dateLayout = "02.", "Jan"
+	}
+	labelWidth := len(labelLayout)
+
+	var lastDate string
+	var nextDatePos int
+	for i := 0; i+labelWidth <= dataPoints; i += labelWidth + 1 {
+		t := tr.From.Add(time.Duration(i) * step).In(tz)
+		copy(lines[0][i:], []rune(t.Format(labelLayout)))
+		date := t.Format(dateLayout)
+		if date != lastDate && i >= nextDatePos && i+len(date) <= dataPoints {
+			copy(lines[1][i:], []rune(date))
+			lastDate = date
+			nextDatePos = i + len(date) + 1
+		}
+	}
+	return [2]string{string(lines[0]), string(lines[1])}
+}
+
+func userLocation(user *User) *time.Location {
+	tz, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return tz
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	util "github.com/automato-io/binocs-cli/util"
 	"github.com/automato-io/tablewriter"
 	"github.com/spf13/cobra"
 )
@@ -26,7 +27,11 @@ List the regions Binocs makes requests from
 		var tableData [][]string
 		for _, v := range supportedRegions {
 			regionAlias := regionAliases[v]
-			tableRow := []string{regionAlias}
+			regionType := colorFaint.Sprint("public")
+			if util.StringInSlice(v, privateRegions) {
+				regionType = "private"
+			}
+			tableRow := []string{regionAlias, regionType}
 			tableData = append(tableData, tableRow)
 		}
 
@@ -36,6 +41,11 @@ List the regions Binocs makes requests from
 				Priority:  1,
 				Alignment: tablewriter.ALIGN_LEFT,
 			},
+			{
+				Header:    "TYPE",
+				Priority:  2,
+				Alignment: tablewriter.ALIGN_LEFT,
+			},
 		}
 
 		table := composeTable(tableData, columnDefinitions)
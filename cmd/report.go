@@ -0,0 +1,499 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	reportFormatMarkdown = "md"
+	reportFormatHTML     = "html"
+	reportMonthLayout    = "2006-01"
+	reportTimeLayout     = "2006-01-02 15:04"
+	svgChartWidth        = 600
+	svgChartHeight       = 80
+	svgColorGreen        = "#2da44e"
+	svgColorYellow       = "#d4a72c"
+	svgColorRed          = "#cf222e"
+	svgColorGrey         = "#d0d7de"
+)
+
+// reportData is what report templates are executed with
+type reportData struct {
+	Title     string
+	Month     string
+	Generated string
+	Timezone  string
+	Checks    []reportCheck
+}
+
+type reportCheck struct {
+	Check             Check
+	Metrics           MetricsResponse
+	Downtime          string
+	Incidents         []reportIncident
+	UptimeChart       string
+	ResponseTimeChart string
+
+	// UptimeChartFile and ResponseTimeChartFile are paths of the charts written next to a Markdown report
+	UptimeChartFile       string
+	ResponseTimeChartFile string
+}
+
+type reportIncident struct {
+	Ident    string
+	Opened   string
+	Closed   string
+	Duration string
+	Note     string
+}
+
+// `report` flags
+var (
+	reportFlagMonth  string
+	reportFlagChecks []string
+	reportFlagFormat string
+	reportFlagOut    string
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringVar(&reportFlagMonth, "month", "", "month to report on, e.g. 2026-09; default previous month")
+	reportCmd.Flags().StringSliceVar(&reportFlagChecks, "checks", []string{"all"}, "checks to report on; can be either \"all\", or one or more check identifiers")
+	reportCmd.Flags().StringVar(&reportFlagFormat, "format", reportFormatMarkdown, "report format, \"md\" or \"html\"")
+	reportCmd.Flags().StringVarP(&reportFlagOut, "out", "o", "", "write report to this file instead of standard output")
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate monthly uptime report",
+	Long: `
+Generate monthly uptime report in Markdown or HTML.
+
+The report contains uptime, Apdex, MRT, total downtime, daily uptime and response time charts, and incidents of each check.
+HTML reports are self-contained, with inline CSS and SVG charts, so that they can be emailed or archived.
+Markdown reports written with --out link to SVG charts saved next to the report, e.g. report-abcdef1-uptime.svg;
+Markdown printed to standard output has no charts.
+`,
+	Example:           `  binocs report --month 2026-09 --checks all --format html -o report-2026-09.html`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		if reportFlagFormat != reportFormatMarkdown && reportFlagFormat != reportFormatHTML {
+			handleErr(fmt.Errorf("Invalid format provided, use \"md\" or \"html\""))
+		}
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading report...")
+
+		user, err := fetchUser()
+		if err != nil {
+			handleErr(err)
+		}
+		tz := userLocation(&user)
+		tr, err := parseReportMonth(reportFlagMonth, tz)
+		if err != nil {
+			spin.Stop()
+			handleErr(err)
+		}
+
+		var checks []Check
+		if len(reportFlagChecks) == 1 && reportFlagChecks[0] == "all" {
+			checks, err = fetchChecks(url.Values{})
+			if err != nil {
+				handleErr(err)
+			}
+			sort.Slice(checks, func(i, j int) bool {
+				return strings.ToLower(checks[i].Name) < strings.ToLower(checks[j].Name)
+			})
+		} else {
+			for _, ident := range reportFlagChecks {
+				respData, err := util.BinocsAPI("/checks/"+ident, http.MethodGet, []byte{})
+				if err != nil {
+					handleErr(err)
+				}
+				var check Check
+				err = json.Unmarshal(respData, &check)
+				if err != nil {
+					handleErr(err)
+				}
+				checks = append(checks, check)
+			}
+		}
+		if len(checks) == 0 {
+			spin.Stop()
+			fmt.Println("No checks yet")
+			return
+		}
+
+		data := reportData{
+			Title:     "Uptime report " + tr.From.Format("January 2006"),
+			Month:     tr.From.Format("January 2006"),
+			Generated: time.Now().In(tz).Format(reportTimeLayout),
+			Timezone:  tz.String(),
+		}
+		for _, c := range checks {
+			spin.Suffix = colorFaint.Sprintf(" loading report of check %s...", c.Ident)
+			rc, err := composeReportCheck(c, tr, tz)
+			if err != nil {
+				handleErr(err)
+			}
+			data.Checks = append(data.Checks, rc)
+		}
+
+		var out io.Writer = os.Stdout
+		if len(reportFlagOut) > 0 {
+			if reportFlagFormat == reportFormatMarkdown {
+				err = writeReportCharts(reportFlagOut, data.Checks)
+				if err != nil {
+					handleErr(err)
+				}
+			}
+			f, err := os.Create(reportFlagOut)
+			if err != nil {
+				handleErr(err)
+			}
+			defer f.Close()
+			out = f
+		}
+		spin.Stop()
+		err = renderReport(out, reportFlagFormat, data)
+		if err != nil {
+			handleErr(err)
+		}
+		if len(reportFlagOut) > 0 {
+			fmt.Println("Report written to " + reportFlagOut)
+		}
+	},
+}
+
+// parseReportMonth returns the calendar month in the user's timezone as a range with daily data points,
+// cut at now for the current month
+func parseReportMonth(month string, tz *time.Location) (timeRange, error) {
+	var tr timeRange
+	now := time.Now().In(tz)
+	if len(month) == 0 {
+		tr.From = time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, tz)
+	} else {
+		from, err := time.ParseInLocation(reportMonthLayout, month, tz)
+		if err != nil {
+			return tr, fmt.Errorf("Invalid month provided: %s; use e.g. 2026-09", month)
+		}
+		tr.From = from
+	}
+	if !tr.From.Before(now) {
+		return tr, fmt.Errorf("Invalid month provided: %s is in the future", tr.From.Format(reportMonthLayout))
+	}
+	tr.To = tr.From.AddDate(0, 1, 0)
+	if tr.To.After(now) {
+		tr.To = now
+	}
+	tr.Points = int(math.Ceil(tr.To.Sub(tr.From).Hours() / 24))
+	return tr, nil
+}
+
+func composeReportCheck(c Check, tr timeRange, tz *time.Location) (reportCheck, error) {
+	rc := reportCheck{Check: c}
+
+	urlValues := url.Values{}
+	tr.setURLValues(&urlValues, false)
+	metrics, err := fetchMetrics(c.Ident, &urlValues)
+	if err != nil {
+		return rc, err
+	}
+	rc.Metrics = metrics
+
+	dailyUptimeURLValues := url.Values{}
+	dailyUptimeURLValues.Set("from", tr.From.Format(calendarDateLayout))
+	dailyUptimeURLValues.Set("to", tr.To.Add(-time.Second).Format(calendarDateLayout))
+	dailyUptimeURLValues.Set("timezone", tz.String())
+	days, err := fetchDailyUptime(c.Ident, dailyUptimeURLValues)
+	if err != nil {
+		return rc, err
+	}
+	rc.UptimeChart = drawSVGUptimeChart(days, tr)
+
+	responseTimeURLValues := url.Values{}
+	tr.setURLValues(&responseTimeURLValues, true)
+	respData, err := util.BinocsAPI("/checks/"+c.Ident+"/response-time?"+responseTimeURLValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return rc, err
+	}
+	responseTime := make([]ResponseTimeResponse, 0)
+	err = json.Unmarshal(respData, &responseTime)
+	if err != nil {
+		return rc, err
+	}
+	rc.ResponseTimeChart = drawSVGResponseTimeChart(responseTime, c.Target)
+
+	incidentsURLValues := url.Values{}
+	incidentsURLValues.Set("check", c.Ident)
+	tr.setURLValues(&incidentsURLValues, false)
+	incidents, err := fetchIncidents(incidentsURLValues)
+	if err != nil {
+		return rc, err
+	}
+	var downtime time.Duration
+	for _, v := range incidents {
+		ri := reportIncident{
+			Ident:    v.Ident,
+			Opened:   v.Opened,
+			Closed:   v.Closed,
+			Duration: util.OutputDurationWithDays(v.Duration),
+			Note:     v.IncidentNote,
+		}
+		opened, err := time.Parse(statusHistoryTimeLayout, v.Opened)
+		if err == nil {
+			ri.Opened = opened.In(tz).Format(reportTimeLayout)
+		}
+		closed, err := time.Parse(statusHistoryTimeLayout, v.Closed)
+		switch {
+		case v.IncidentState == incidentStateOpen:
+			ri.Closed = "ongoing"
+			closed = tr.To
+		case err == nil:
+			ri.Closed = closed.In(tz).Format(reportTimeLayout)
+		default:
+			duration, _ := time.ParseDuration(v.Duration)
+			closed = opened.Add(duration)
+		}
+		downtime += reportOverlap(opened, closed, tr)
+		rc.Incidents = append(rc.Incidents, ri)
+	}
+	rc.Downtime = util.OutputDurationWithDays(downtime.String())
+	return rc, nil
+}
+
+// writeReportCharts saves the SVG charts of each check next to the Markdown report at path, and
+// sets the relative paths the report links them with
+func writeReportCharts(path string, checks []reportCheck) error {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for i, rc := range checks {
+		for _, chart := range []struct {
+			name string
+			svg  string
+			file *string
+		}{
+			{"uptime", rc.UptimeChart, &checks[i].UptimeChartFile},
+			{"response-time", rc.ResponseTimeChart, &checks[i].ResponseTimeChartFile},
+		} {
+			file := base + "-" + rc.Check.Ident + "-" + chart.name + ".svg"
+			err := os.WriteFile(filepath.Join(filepath.Dir(path), file), []byte(chart.svg), 0644)
+			if err != nil {
+				return err
+			}
+			*chart.file = file
+		}
+	}
+	return nil
+}
+
+// reportOverlap is the part of the from-to interval that falls into the reported range
+func reportOverlap(from, to time.Time, tr timeRange) time.Duration {
+	if from.IsZero() {
+		return 0
+	}
+	if from.Before(tr.From) {
+		from = tr.From
+	}
+	if to.After(tr.To) {
+		to = tr.To
+	}
+	if !to.After(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+func renderReport(w io.Writer, format string, data reportData) error {
+	if format == reportFormatHTML {
+		tmpl, err := htmltemplate.New("report").Funcs(htmltemplate.FuncMap{
+			"svg": func(s string) htmltemplate.HTML { return htmltemplate.HTML(s) },
+		}).Parse(reportHTMLTemplate)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, data)
+	}
+	tmpl, err := texttemplate.New("report").Funcs(texttemplate.FuncMap{
+		"cell": func(s string) string {
+			return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+		},
+	}).Parse(reportMarkdownTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// drawSVGUptimeChart draws a bar per day of the range, colored the same way as uptime in the terminal;
+// bars start at a floor just below the lowest day, so that dips of a fraction of a percent are visible
+func drawSVGUptimeChart(days []DailyUptimeResponse, tr timeRange) string {
+	uptimes := make(map[string]float64)
+	lowest := 100.0
+	for _, d := range days {
+		uptime, err := strconv.ParseFloat(d.Uptime, 64)
+		if err != nil {
+			continue
+		}
+		uptimes[d.Date] = uptime
+		lowest = math.Min(lowest, uptime)
+	}
+	floor := math.Max(0, math.Min(99, math.Floor((lowest-(100-lowest)/4)*10)/10))
+	values := make([]float64, tr.Points)
+	colors := make([]string, tr.Points)
+	for i := range values {
+		date := tr.From.AddDate(0, 0, i).Format(calendarDateLayout)
+		uptime, ok := uptimes[date]
+		if !ok {
+			values[i], colors[i] = 0, svgColorGrey
+			continue
+		}
+		values[i], colors[i] = (uptime-floor)/(100-floor), svgUptimeColor(uptime)
+	}
+	return drawSVGBarChart(values, colors, fmt.Sprintf("Daily uptime, %s to 100 %%", strconv.FormatFloat(floor, 'f', -1, 64)))
+}
+
+// drawSVGResponseTimeChart draws a bar per data point, colored by the check's Apdex target
+func drawSVGResponseTimeChart(rt []ResponseTimeResponse, target float64) string {
+	values := make([]float64, len(rt))
+	colors := make([]string, len(rt))
+	var maxMRT float64
+	for i, v := range rt {
+		mrt, err := strconv.ParseFloat(v.MRT, 64)
+		if err != nil {
+			values[i] = math.NaN()
+			continue
+		}
+		values[i] = mrt
+		maxMRT = math.Max(maxMRT, mrt)
+		switch {
+		case mrt <= target:
+			colors[i] = svgColorGreen
+		case mrt <= 4*target:
+			colors[i] = svgColorYellow
+		default:
+			colors[i] = svgColorRed
+		}
+	}
+	for i := range values {
+		if math.IsNaN(values[i]) || maxMRT == 0 {
+			values[i], colors[i] = 0, svgColorGrey
+			continue
+		}
+		values[i] = values[i] / maxMRT
+	}
+	return drawSVGBarChart(values, colors, fmt.Sprintf("Response time, max %.3f s", maxMRT))
+}
+
+func svgUptimeColor(uptime float64) string {
+	if uptime == 100.0 {
+		return svgColorGreen
+	}
+	if uptime > 99.9 {
+		return svgColorYellow
+	}
+	return svgColorRed
+}
+
+// drawSVGBarChart draws values between 0 and 1 as bars of given colors
+func drawSVGBarChart(values []float64, colors []string, title string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" preserveAspectRatio="none" role="img"><title>%s</title>`,
+		svgChartWidth, svgChartHeight, svgChartWidth, svgChartHeight, htmltemplate.HTMLEscapeString(title))
+	if len(values) > 0 {
+		barWidth := float64(svgChartWidth) / float64(len(values))
+		for i, v := range values {
+			h := math.Max(v*svgChartHeight, 2)
+			fmt.Fprintf(&b, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`,
+				float64(i)*barWidth+0.5, svgChartHeight-h, math.Max(barWidth-1, 0.5), h, colors[i])
+		}
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+const reportMarkdownTemplate = `# {{ .Title }}
+
+Generated {{ .Generated }} ({{ .Timezone }})
+{{ range .Checks }}
+## {{ if .Check.Name }}{{ .Check.Name }}{{ else }}{{ .Check.Resource }}{{ end }}
+
+{{ .Check.Protocol }} {{ .Check.Resource }} ({{ .Check.Ident }})
+
+| Uptime | Apdex | MRT | Downtime | Incidents |
+|---|---|---|---|---|
+| {{ .Metrics.Uptime }} % | {{ .Metrics.Apdex }} | {{ .Metrics.MRT }} s | {{ .Downtime }} | {{ len .Incidents }} |
+
+{{ if .UptimeChartFile }}
+![Daily uptime]({{ .UptimeChartFile }})
+
+![Response time]({{ .ResponseTimeChartFile }})
+{{ end }}{{ if .Incidents }}
+| Incident | Opened | Closed | Duration | Note |
+|---|---|---|---|---|
+{{ range .Incidents }}| {{ .Ident }} | {{ .Opened }} | {{ .Closed }} | {{ .Duration }} | {{ cell .Note }} |
+{{ end }}{{ else }}
+No incidents.
+{{ end }}{{ end }}`
+
+const reportHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292f; max-width: 720px; margin: 2em auto; padding: 0 1em; }
+h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+h2 { font-size: 1.2em; margin: 2em 0 0.2em; }
+.muted { color: #57606a; font-size: 0.9em; }
+table { border-collapse: collapse; width: 100%; margin: 1em 0; font-size: 0.9em; }
+th, td { border: 1px solid #d0d7de; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+figure { margin: 1em 0; }
+figcaption { color: #57606a; font-size: 0.8em; }
+svg { width: 100%; height: auto; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p class="muted">Generated {{ .Generated }} ({{ .Timezone }})</p>
+{{ range .Checks }}
+<h2>{{ if .Check.Name }}{{ .Check.Name }}{{ else }}{{ .Check.Resource }}{{ end }}</h2>
+<p class="muted">{{ .Check.Protocol }} {{ .Check.Resource }} ({{ .Check.Ident }})</p>
+<table>
+<tr><th>Uptime</th><th>Apdex</th><th>MRT</th><th>Downtime</th><th>Incidents</th></tr>
+<tr><td>{{ .Metrics.Uptime }} %</td><td>{{ .Metrics.Apdex }}</td><td>{{ .Metrics.MRT }} s</td><td>{{ .Downtime }}</td><td>{{ len .Incidents }}</td></tr>
+</table>
+<figure>{{ svg .UptimeChart }}<figcaption>Daily uptime</figcaption></figure>
+<figure>{{ svg .ResponseTimeChart }}<figcaption>Response time</figcaption></figure>
+{{ if .Incidents }}
+<table>
+<tr><th>Incident</th><th>Opened</th><th>Closed</th><th>Duration</th><th>Note</th></tr>
+{{ range .Incidents }}<tr><td>{{ .Ident }}</td><td>{{ .Opened }}</td><td>{{ .Closed }}</td><td>{{ .Duration }}</td><td>{{ .Note }}</td></tr>
+{{ end }}</table>
+{{ else }}
+<p>No incidents.</p>
+{{ end }}{{ end }}
+</body>
+</html>
+`
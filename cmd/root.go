@@ -4,14 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"os"
-	"os/exec"
 	"regexp"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/automato-io/binocs-cli/util"
@@ -86,7 +88,8 @@ const (
 )
 
 const (
-	watchInterval = time.Duration(5 * time.Second)
+	defaultWatchInterval = 5
+	minWatchInterval     = time.Duration(1 * time.Second)
 )
 
 var (
@@ -98,6 +101,7 @@ var (
 
 var (
 	supportedRegions = []string{}
+	privateRegions   = []string{}
 	defaultRegions   = []string{ // @todo fetch via API
 		"us-east-1",
 		"us-west-1",
@@ -187,53 +191,129 @@ func Execute() {
 	}
 }
 
-func runAsWatch() {
-	// windows are unsupported because github.com/iamacarpet/go-winpty crashes during build in CI
-	if runtime.GOOS == "windows" {
-		handleErr(fmt.Errorf("The --watch flag is not currently supported on Windows."))
+// runAsWatch renders the command output every interval in a full-screen view, until the user quits;
+// errors are shown in the footer while the last successful output stays on screen
+func runAsWatch(render func(w io.Writer) error, interval time.Duration) {
+	if interval < minWatchInterval {
+		handleErr(fmt.Errorf("Watch interval must be at least %d second(s)", int(minWatchInterval.Seconds())))
 	}
-	app, screen, viewer, err := initWatchEnv()
+	spin.Disable()
+	app, screen, viewer, footer, err := initWatchEnv()
 	if err != nil {
-		screen.Suspend()
 		handleErr(err)
 	}
-	args := os.Args
-	args = append(args, "--quiet")
-	for i, v := range args {
-		if v == "--watch" {
-			args = append(args[:i], args[i+1:]...)
+	var mu sync.Mutex
+	var paused bool
+	var updated, next time.Time
+	var renderErr error
+	refreshNow := make(chan struct{}, 1)
+	drawFooter := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		var status string
+		switch {
+		case updated.IsZero() && renderErr == nil:
+			status = "loading..."
+		case !updated.IsZero():
+			status = "last updated " + updated.Format("15:04:05")
+		}
+		if paused {
+			status = status + " · [yellow]paused[-]"
+		} else if !next.IsZero() {
+			status = status + fmt.Sprintf(" · next refresh in %ds", int(math.Max(0, math.Ceil(time.Until(next).Seconds()))))
 		}
+		if renderErr != nil {
+			status = status + " · [red]" + tview.Escape(renderErr.Error()) + "[-]"
+		}
+		footer.SetText(strings.TrimPrefix(status, " · ") + "  [::d]p pause · r refresh · q quit[::-]")
 	}
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		switch event.Rune() {
+		case 'q':
+			app.Stop()
+		case 'p', ' ':
+			mu.Lock()
+			paused = !paused
+			due := !paused && time.Now().After(next)
+			mu.Unlock()
+			if due {
+				select {
+				case refreshNow <- struct{}{}:
+				default:
+				}
+			}
+			drawFooter()
+		case 'r':
+			select {
+			case refreshNow <- struct{}{}:
+			default:
+			}
+		default:
+			return event
+		}
+		return nil
+	})
 	go func() {
 		for {
-			var buf bytes.Buffer
-			var cmdStart, cmdEnd time.Time
-			var watchIntervalRemainder time.Duration
-			cmdStart = time.Now()
-			cmd := exec.Command(args[0], args[1:]...)
-			err := util.CmdOutput(cmd, &buf)
-			if err != nil {
-				screen.Suspend()
-				handleErr(err)
+			width, _ := screen.Size()
+			buf := &watchBuffer{width: width}
+			err := render(buf)
+			if buf.bell {
+				_ = screen.Beep()
+			}
+			mu.Lock()
+			renderErr = err
+			if err == nil {
+				updated = time.Now()
 			}
+			next = time.Now().Add(interval)
+			mu.Unlock()
 			app.QueueUpdateDraw(func() {
-				screen.Clear()
-				viewer.SetText(tview.TranslateANSI(buf.String()))
+				if err == nil {
+					row, column := viewer.GetScrollOffset()
+					viewer.SetText(tview.TranslateANSI(buf.String())).ScrollTo(row, column)
+				}
+				drawFooter()
 			})
-			cmdEnd = time.Now()
-			watchIntervalRemainder = watchInterval - cmdEnd.Sub(cmdStart)
-			if watchIntervalRemainder > 0 {
-				time.Sleep(watchIntervalRemainder)
+			timer := time.NewTimer(interval)
+			for waiting := true; waiting; {
+				select {
+				case <-timer.C:
+					mu.Lock()
+					waiting = paused
+					if paused {
+						timer.Reset(time.Second)
+					}
+					mu.Unlock()
+				case <-refreshNow:
+					timer.Stop()
+					waiting = false
+				}
 			}
 		}
 	}()
+	go func() {
+		for range time.Tick(time.Second) {
+			app.QueueUpdateDraw(drawFooter)
+		}
+	}()
 	err = app.Run()
 	if err != nil {
-		screen.Suspend()
 		handleErr(err)
 	}
 }
 
+// watchBuffer collects output of a single --watch refresh; width is the width of the watch view,
+// and render sets bell to ring the terminal bell once the refresh is drawn
+type watchBuffer struct {
+	bytes.Buffer
+	width int
+	bell  bool
+}
+
 func init() {
 	cobra.OnInitialize(initRuntimeSystemSpecifics)
 	cobra.OnInitialize(initConfig)
@@ -342,32 +422,33 @@ func initGlobalFlags() {
 	}
 }
 
-func initWatchEnv() (*tview.Application, tcell.Screen, *tview.TextView, error) {
+func initWatchEnv() (*tview.Application, tcell.Screen, *tview.TextView, *tview.TextView, error) {
 	screen, err := tcell.NewScreen()
 	if err != nil {
-		handleWarn(err.Error())
-		return nil, screen, nil, err
+		return nil, screen, nil, nil, err
 	}
 	err = screen.Init()
 	if err != nil {
-		handleWarn(err.Error())
-		return nil, screen, nil, err
+		return nil, screen, nil, nil, err
 	}
 	app := tview.NewApplication()
 	app.SetScreen(screen)
 	viewer := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetTextColor(tcell.ColorDefault)
 	viewer.SetBackgroundColor(tcell.ColorDefault)
+	footer := tview.NewTextView().SetDynamicColors(true).SetTextColor(tcell.ColorDefault)
+	footer.SetBackgroundColor(tcell.ColorDefault)
 	flex := tview.NewFlex().SetDirection(tview.FlexRow)
 	flex.AddItem(viewer, 0, 1, true)
+	flex.AddItem(footer, 1, 0, false)
 	app.SetRoot(flex, true)
-	return app, screen, viewer, err
+	return app, screen, viewer, footer, err
 }
 
 //
 
-func printZeroCreditsWarning() {
+func printZeroCreditsWarning(w io.Writer) {
 	creditsBalanceWarning := color.RedString("WARNING: ") + "Your credit balance reached zero and all your checks were paused.\nIf you wish to continue using Binocs, please visit the Settings page at " + colorUnderline.Sprint("https://binocs.sh/settings") + " to purchase additional credits.\nYour checks will resume once you top up credits."
-	tableCreditsBalanceWarning := tablewriter.NewWriter(os.Stdout)
+	tableCreditsBalanceWarning := tablewriter.NewWriter(w)
 	tableCreditsBalanceWarning.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
 	tableCreditsBalanceWarning.SetBorderSymbols(tablewriter.BorderSymbols{
 		Horizontal:  colorFaint.Sprint("─"),
@@ -397,7 +478,18 @@ type tableColumnDefinition struct {
 }
 
 func composeTable(data [][]string, columnDefs []tableColumnDefinition) *tablewriter.Table {
-	physicalWidth, _, _ := term.GetSize(int(os.Stdout.Fd()))
+	return composeTableTo(os.Stdout, data, columnDefs)
+}
+
+// composeTableTo hides lowest priority columns until the table fits the width of the terminal or watch view w writes to
+func composeTableTo(w io.Writer, data [][]string, columnDefs []tableColumnDefinition) *tablewriter.Table {
+	var physicalWidth int
+	switch o := w.(type) {
+	case *os.File:
+		physicalWidth, _, _ = term.GetSize(int(o.Fd()))
+	case *watchBuffer:
+		physicalWidth = o.width
+	}
 	tableCellWidths := make([]int, len(columnDefs))
 	for _, v := range data {
 		for i, w := range v {
@@ -433,6 +525,10 @@ func composeTable(data [][]string, columnDefs []tableColumnDefinition) *tablewri
 		if currentTableWidth <= physicalWidth {
 			break
 		}
+		if _, ok := w.(*watchBuffer); ok && currentColumnsCount == 1 {
+			// a watch refresh must not exit; the table is redrawn once the terminal is wide enough
+			break
+		}
 		for i, c := range columnDefs {
 			if !c.hidden && c.Priority == int8(currentLowestPriority) {
 				if currentShortestWidth == 0 || tableCellWidths[i] < currentShortestWidth {
@@ -447,7 +543,7 @@ func composeTable(data [][]string, columnDefs []tableColumnDefinition) *tablewri
 		columnDefs[nextToHide].hidden = true
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(w)
 	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
 	table.SetBorderSymbols(tablewriter.BorderSymbols{
 		Horizontal:  colorFaint.Sprint("─"),
@@ -514,6 +610,12 @@ func loadSupportedRegions() {
 		handleErr(err)
 	}
 	supportedRegions = regionsResponse.Regions
+	privateRegions = []string{}
+	for _, r := range regionsResponse.PrivateRegions {
+		supportedRegions = append(supportedRegions, r.ID)
+		privateRegions = append(privateRegions, r.ID)
+		regionAliases[r.ID] = r.Name
+	}
 	sort.Strings(supportedRegions)
 }
 
@@ -0,0 +1,402 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	grafanaTargetApdex         = "apdex"
+	grafanaTargetResponseCodes = "response_codes"
+	grafanaTargetHeatmap       = "response_time_heatmap"
+	grafanaTargetUptime        = "uptime"
+	grafanaTargetMRT           = "mrt"
+	grafanaTargetApdexTotal    = "apdex_total"
+	grafanaMinCacheTTL         = 30 * time.Second
+	grafanaMaxRequestBody      = 1 << 20
+)
+
+var grafanaTargets = []string{grafanaTargetApdex, grafanaTargetResponseCodes, grafanaTargetHeatmap, grafanaTargetUptime, grafanaTargetMRT, grafanaTargetApdexTotal}
+
+var grafanaHeatmapBuckets = [8]string{"0-0.125s", "0.125-0.25s", "0.25-0.5s", "0.5-1s", "1-2s", "2-4s", "4-8s", "8s+ or error"}
+
+// grafanaRange is the dashboard time range of /query and /annotations requests
+type grafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type grafanaQueryRequest struct {
+	Range         grafanaRange `json:"range"`
+	MaxDataPoints int          `json:"maxDataPoints"`
+	Targets       []struct {
+		Target string `json:"target"`
+		RefID  string `json:"refId"`
+	} `json:"targets"`
+}
+
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+type grafanaAnnotationRequest struct {
+	Range      grafanaRange `json:"range"`
+	Annotation struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+	} `json:"annotation"`
+}
+
+type grafanaAnnotation struct {
+	Time    int64    `json:"time"`
+	TimeEnd int64    `json:"timeEnd,omitempty"`
+	Title   string   `json:"title"`
+	Text    string   `json:"text"`
+	Tags    []string `json:"tags"`
+}
+
+type grafanaSearchResult struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// `serve grafana` flags
+var (
+	serveGrafanaFlagListen string
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.AddCommand(serveGrafanaCmd)
+
+	serveGrafanaCmd.Flags().StringVar(&serveGrafanaFlagListen, "listen", ":3100", "address to serve the datasource API on")
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve Binocs data to other tools",
+	Long: `
+Serve Binocs data to other tools.
+`,
+	DisableAutoGenTag: true,
+}
+
+var serveGrafanaCmd = &cobra.Command{
+	Use:   "grafana",
+	Short: "Serve a Grafana JSON datasource",
+	Long: `
+Serve check data to Grafana, implementing the JSON datasource API (/search, /query, /annotations).
+
+Query targets have the form <check ident>/<series>, where series is one of:
+  apdex                   Apdex over time
+  response_codes          number of 1xx, 2xx, 3xx, 4xx, 5xx responses and errors over time
+  response_time_heatmap   number of responses in each response time range over time
+  uptime, mrt, apdex_total
+                          a single value over the dashboard time range
+
+Incidents are served as annotations; set the annotation query to a check ident to only show incidents of that check.
+Results are cached until the time bucket they belong to changes, so that dashboard refreshes do not hit the API each time.
+`,
+	Example:           `  binocs serve grafana --listen :3100`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+		spin.Disable()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		ds := &grafanaDatasource{cache: map[string]grafanaCacheEntry{}}
+		ds.serve(ctx, serveGrafanaFlagListen)
+		log.Printf("grafana datasource stopped")
+	},
+}
+
+type grafanaCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// grafanaDatasource caches API results per target and time bucket
+type grafanaDatasource struct {
+	sync.Mutex
+	cache map[string]grafanaCacheEntry
+}
+
+func (ds *grafanaDatasource) cached(key string, ttl time.Duration, load func() (interface{}, error)) (interface{}, error) {
+	now := time.Now()
+	ds.Lock()
+	entry, ok := ds.cache[key]
+	ds.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.value, nil
+	}
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if ttl < grafanaMinCacheTTL {
+		ttl = grafanaMinCacheTTL
+	}
+	ds.Lock()
+	for k, v := range ds.cache {
+		if now.After(v.expires) {
+			delete(ds.cache, k)
+		}
+	}
+	ds.cache[key] = grafanaCacheEntry{value: value, expires: now.Add(ttl)}
+	ds.Unlock()
+	return value, nil
+}
+
+// bucketRange aligns the dashboard range to steps of a custom timeRange, so that
+// queries made within the same step share cached results
+func bucketRange(r grafanaRange, maxDataPoints int) timeRange {
+	tr := timeRange{From: r.From, To: r.To}
+	if tr.To.After(time.Now()) || tr.To.IsZero() {
+		tr.To = time.Now()
+	}
+	if tr.From.IsZero() || !tr.From.Before(tr.To.Add(-minRangeDuration)) {
+		tr.From = tr.To.Add(-minRangeDuration)
+	}
+	if maxRange := rangeSteps[len(rangeSteps)-1] * maxRangeDataPoints; tr.To.Sub(tr.From) > maxRange {
+		tr.From = tr.To.Add(-maxRange)
+	}
+	step := tr.step()
+	tr.From = tr.From.Truncate(step)
+	tr.To = tr.To.Truncate(step).Add(step)
+	if maxDataPoints <= 0 || maxDataPoints > maxRangeDataPoints {
+		maxDataPoints = maxRangeDataPoints
+	}
+	if tr.dataPoints() > maxDataPoints {
+		tr.Points = maxDataPoints
+	}
+	return tr
+}
+
+func (ds *grafanaDatasource) serve(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/search", ds.handleSearch)
+	mux.HandleFunc("/query", ds.handleQuery)
+	mux.HandleFunc("/annotations", ds.handleAnnotations)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	log.Printf("serving grafana datasource at http://%s/", addr)
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		handleErr(err)
+	}
+}
+
+func (ds *grafanaDatasource) handleSearch(w http.ResponseWriter, r *http.Request) {
+	value, err := ds.cached("search", grafanaMinCacheTTL, func() (interface{}, error) {
+		checks, err := fetchChecks(url.Values{})
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(checks, func(i, j int) bool {
+			return strings.ToLower(checks[i].Name) < strings.ToLower(checks[j].Name)
+		})
+		results := []grafanaSearchResult{}
+		for _, c := range checks {
+			name := c.Name
+			if len(name) == 0 {
+				name = c.Resource
+			}
+			for _, t := range grafanaTargets {
+				results = append(results, grafanaSearchResult{Text: name + " " + t, Value: c.Ident + "/" + t})
+			}
+		}
+		return results, nil
+	})
+	writeGrafanaResponse(w, value, err)
+}
+
+func (ds *grafanaDatasource) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var req grafanaQueryRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, grafanaMaxRequestBody)).Decode(&req)
+	if err != nil {
+		http.Error(w, "invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	tr := bucketRange(req.Range, req.MaxDataPoints)
+	series := []grafanaSeries{}
+	for _, t := range req.Targets {
+		parts := strings.SplitN(t.Target, "/", 2)
+		if len(parts) != 2 || !util.StringInSlice(parts[1], grafanaTargets) {
+			http.Error(w, "invalid target: "+t.Target, http.StatusBadRequest)
+			return
+		}
+		key := fmt.Sprintf("query %s %d %d %d", t.Target, tr.From.Unix(), tr.To.Unix(), tr.dataPoints())
+		value, err := ds.cached(key, tr.step(), func() (interface{}, error) {
+			return queryGrafanaSeries(parts[0], parts[1], tr)
+		})
+		if err != nil {
+			writeGrafanaResponse(w, nil, err)
+			return
+		}
+		series = append(series, value.([]grafanaSeries)...)
+	}
+	writeGrafanaResponse(w, series, nil)
+}
+
+// queryGrafanaSeries maps a target onto the API endpoint it comes from; data points are timestamped
+// by the end of their step, in milliseconds
+func queryGrafanaSeries(ident, target string, tr timeRange) ([]grafanaSeries, error) {
+	urlValues := url.Values{}
+	tr.setURLValues(&urlValues, true)
+	step := tr.step()
+	// timestamp prefers the end of the step returned by the API; otherwise, like charts do, a series shorter
+	// than requested is aligned to the end of the range, so the i-th of n points ends (n-1-i) steps before tr.To
+	timestamp := func(i, n int, to string) float64 {
+		for _, layout := range []string{statusHistoryTimeLayout, time.RFC3339} {
+			if t, err := time.Parse(layout, to); err == nil {
+				return float64(t.UnixMilli())
+			}
+		}
+		return float64(tr.To.Add(-time.Duration(n-1-i) * step).UnixMilli())
+	}
+
+	switch target {
+	case grafanaTargetApdex:
+		apdex, err := fetchApdex(ident, &urlValues)
+		if err != nil {
+			return nil, err
+		}
+		s := grafanaSeries{Target: ident + " apdex", Datapoints: [][2]float64{}}
+		for i, v := range apdex {
+			if value, err := strconv.ParseFloat(v.Apdex, 64); err == nil {
+				s.Datapoints = append(s.Datapoints, [2]float64{value, timestamp(i, len(apdex), v.To)})
+			}
+		}
+		return []grafanaSeries{s}, nil
+
+	case grafanaTargetResponseCodes:
+		codes, err := fetchResponseCodes(ident, &urlValues)
+		if err != nil {
+			return nil, err
+		}
+		names := []string{"1xx", "2xx", "3xx", "4xx", "5xx", "Err"}
+		series := make([]grafanaSeries, len(names))
+		for j, name := range names {
+			series[j] = grafanaSeries{Target: ident + " " + name, Datapoints: [][2]float64{}}
+		}
+		for i, v := range codes {
+			for j, count := range []int{v.Xx1, v.Xx2, v.Xx3, v.Xx4, v.Xx5, v.Err} {
+				series[j].Datapoints = append(series[j].Datapoints, [2]float64{float64(count), timestamp(i, len(codes), v.To)})
+			}
+		}
+		return series, nil
+
+	case grafanaTargetHeatmap:
+		heatmap, err := fetchResponseTimeHeatmap(ident, &urlValues)
+		if err != nil {
+			return nil, err
+		}
+		series := make([]grafanaSeries, len(grafanaHeatmapBuckets))
+		for j, name := range grafanaHeatmapBuckets {
+			series[j] = grafanaSeries{Target: ident + " " + name, Datapoints: [][2]float64{}}
+		}
+		for i, v := range heatmap {
+			for j, count := range []int{v.Rt0, v.Rt1, v.Rt2, v.Rt3, v.Rt4, v.Rt5, v.Rt6, v.Rt7} {
+				series[j].Datapoints = append(series[j].Datapoints, [2]float64{float64(count), timestamp(i, len(heatmap), v.To)})
+			}
+		}
+		return series, nil
+	}
+
+	metricsURLValues := url.Values{}
+	tr.setURLValues(&metricsURLValues, false)
+	metrics, err := fetchMetrics(ident, &metricsURLValues)
+	if err != nil {
+		return nil, err
+	}
+	value := map[string]string{
+		grafanaTargetUptime:     metrics.Uptime,
+		grafanaTargetMRT:        metrics.MRT,
+		grafanaTargetApdexTotal: metrics.Apdex,
+	}[target]
+	s := grafanaSeries{Target: ident + " " + target, Datapoints: [][2]float64{}}
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		s.Datapoints = append(s.Datapoints, [2]float64{v, float64(tr.To.UnixMilli())})
+	}
+	return []grafanaSeries{s}, nil
+}
+
+func (ds *grafanaDatasource) handleAnnotations(w http.ResponseWriter, r *http.Request) {
+	var req grafanaAnnotationRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, grafanaMaxRequestBody)).Decode(&req)
+	if err != nil {
+		http.Error(w, "invalid annotation query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	tr := bucketRange(req.Range, 0)
+	check := strings.TrimSpace(req.Annotation.Query)
+	key := fmt.Sprintf("annotations %s %d %d", check, tr.From.Unix(), tr.To.Unix())
+	value, err := ds.cached(key, tr.step(), func() (interface{}, error) {
+		urlValues := url.Values{}
+		tr.setURLValues(&urlValues, false)
+		if len(check) > 0 {
+			urlValues.Set("check", check)
+		}
+		incidents, err := fetchIncidents(urlValues)
+		if err != nil {
+			return nil, err
+		}
+		annotations := []grafanaAnnotation{}
+		for _, v := range incidents {
+			opened, err := time.Parse(statusHistoryTimeLayout, v.Opened)
+			if err != nil {
+				continue
+			}
+			a := grafanaAnnotation{
+				Time:  opened.UnixMilli(),
+				Title: fmt.Sprintf("Incident %s: %s", v.Ident, v.CheckName),
+				Text:  v.IncidentNote,
+				Tags:  []string{v.CheckIdent, v.IncidentState},
+			}
+			if closed, err := time.Parse(statusHistoryTimeLayout, v.Closed); err == nil {
+				a.TimeEnd = closed.UnixMilli()
+			}
+			annotations = append(annotations, a)
+		}
+		return annotations, nil
+	})
+	writeGrafanaResponse(w, value, err)
+}
+
+func writeGrafanaResponse(w http.ResponseWriter, value interface{}, err error) {
+	if err != nil {
+		log.Printf("cannot load data: %v", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,633 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/automato-io/tablewriter"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// SLO comes from the API as a JSON
+type SLO struct {
+	ID               int       `json:"id,omitempty"`
+	Ident            string    `json:"ident,omitempty"`
+	Name             string    `json:"name,omitempty"`
+	Indicator        string    `json:"indicator,omitempty"`
+	Objective        float64   `json:"objective,omitempty"`
+	Window           int       `json:"window,omitempty"`
+	LatencyThreshold float64   `json:"latency_threshold,omitempty"`
+	BurnRateAlert    float64   `json:"burn_rate_alert,omitempty"`
+	Checks           []string  `json:"checks,omitempty"`
+	Channels         []string  `json:"channels,omitempty"`
+	Status           SLOStatus `json:"status,omitempty"`
+	Created          string    `json:"created,omitempty"`
+	Updated          string    `json:"updated,omitempty"`
+}
+
+// SLOStatus comes from the API as a JSON; attainment values are percentages of good minutes (availability)
+// or good requests (latency) over the SLO window and over the burn rate windows
+type SLOStatus struct {
+	Attainment   string `json:"attainment,omitempty"`
+	Attainment1h string `json:"attainment_1h,omitempty"`
+	Attainment6h string `json:"attainment_6h,omitempty"`
+	Attainment3d string `json:"attainment_3d,omitempty"`
+}
+
+// `slo add` flags
+var (
+	sloAddFlagName             string
+	sloAddFlagIndicator        string
+	sloAddFlagObjective        float64
+	sloAddFlagWindow           int
+	sloAddFlagLatencyThreshold float64
+	sloAddFlagBurnRateAlert    float64
+	sloAddFlagCheck            []string
+	sloAddFlagAttach           []string
+)
+
+const (
+	validSLOIdentPattern     = `^[a-f0-9]{6}$`
+	validIndicatorPattern    = `^(availability|latency)$`
+	sloIndicatorAvailability = "availability"
+	sloIndicatorLatency      = "latency"
+	supportedObjectiveMin    = 50.0
+	supportedObjectiveMax    = 99.999
+	supportedWindowMinimum   = 1
+	supportedWindowMaximum   = 90
+)
+
+func init() {
+	rootCmd.AddCommand(slosCmd)
+
+	rootCmd.AddCommand(sloCmd)
+
+	sloCmd.AddCommand(sloAddCmd)
+	sloCmd.AddCommand(sloDeleteCmd)
+	sloCmd.AddCommand(sloInspectCmd)
+	sloCmd.AddCommand(sloListCmd)
+
+	sloAddCmd.Flags().StringVarP(&sloAddFlagName, "name", "n", "", "SLO name")
+	sloAddCmd.Flags().StringVarP(&sloAddFlagIndicator, "indicator", "i", "", "what the objective is measured on: \"availability\" (share of minutes up) or \"latency\" (share of requests faster than the threshold)")
+	sloAddCmd.Flags().Float64VarP(&sloAddFlagObjective, "objective", "o", 99.9, "objective in percent, e.g. 99.9")
+	sloAddCmd.Flags().IntVarP(&sloAddFlagWindow, "window", "w", 30, "rolling window the objective is evaluated over, in days")
+	sloAddCmd.Flags().Float64VarP(&sloAddFlagLatencyThreshold, "latency_threshold", "", 0, "latency indicator only: response time a request must beat, in seconds; each check's target response time if not set")
+	sloAddCmd.Flags().Float64VarP(&sloAddFlagBurnRateAlert, "burn_rate_alert", "", 0, "notify attached channels when the 1h and 6h burn rates exceed this value, e.g. 14.4 (optional)")
+	sloAddCmd.Flags().StringSliceVarP(&sloAddFlagCheck, "check", "c", []string{}, "checks the objective applies to; can be either \"all\", or one or more check identifiers")
+	sloAddCmd.Flags().StringSliceVar(&sloAddFlagAttach, "attach", []string{}, "channels to notify about burn rate alerts (optional); can be either \"all\", or one or more channel identifiers")
+	sloAddCmd.Flags().SortFlags = false
+}
+
+var sloCmd = &cobra.Command{
+	Use:   "slo",
+	Short: "Manage service level objectives",
+	Long: `
+Manage service level objectives (SLOs) and track their error budgets.
+`,
+	DisableAutoGenTag: true,
+}
+
+var slosCmd = &cobra.Command{
+	Use:               "slos",
+	Args:              cobra.NoArgs,
+	Short:             sloListCmd.Short,
+	Long:              sloListCmd.Long,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		sloListCmd.Run(cmd, args)
+	},
+}
+
+var sloAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a new service level objective",
+	Long: `
+Add a new service level objective, e.g. 99.9 % availability over 30 days, attached to one or more checks.
+
+This command is interactive and asks user for parameters that were not provided as flags.
+`,
+	Aliases:           []string{"create"},
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		var err error
+		var match bool
+
+		match, err = regexp.MatchString(validNamePattern, sloAddFlagName)
+		if err != nil {
+			handleErr(err)
+		} else if !match || sloAddFlagName == "" {
+			validate := func(val interface{}) error {
+				match, err = regexp.MatchString(validNamePattern, val.(string))
+				if err != nil {
+					return err
+				} else if !match || val.(string) == "" {
+					return errors.New("invalid name format")
+				}
+				return nil
+			}
+			prompt := &survey.Input{
+				Message: "SLO name:",
+			}
+			err = survey.AskOne(prompt, &sloAddFlagName, survey.WithValidator(validate))
+			if err != nil {
+				handleErr(err)
+			}
+		}
+
+		match, err = regexp.MatchString(validIndicatorPattern, sloAddFlagIndicator)
+		if err != nil {
+			handleErr(err)
+		} else if !match {
+			prompt := &survey.Select{
+				Message: "Choose indicator:",
+				Options: []string{sloIndicatorAvailability, sloIndicatorLatency},
+			}
+			err = survey.AskOne(prompt, &sloAddFlagIndicator)
+			if err != nil {
+				handleErr(err)
+			}
+		}
+
+		if sloAddFlagObjective < supportedObjectiveMin || sloAddFlagObjective > supportedObjectiveMax {
+			handleErr(fmt.Errorf("Objective must be between %v and %v percent", supportedObjectiveMin, supportedObjectiveMax))
+		}
+		if sloAddFlagWindow < supportedWindowMinimum || sloAddFlagWindow > supportedWindowMaximum {
+			handleErr(fmt.Errorf("Window must be between %d and %d days", supportedWindowMinimum, supportedWindowMaximum))
+		}
+		if sloAddFlagLatencyThreshold != 0 && sloAddFlagIndicator != sloIndicatorLatency {
+			handleErr(fmt.Errorf("--latency_threshold can only be used with the latency indicator"))
+		}
+		if sloAddFlagLatencyThreshold != 0 && (sloAddFlagLatencyThreshold < supportedTargetMinimum || sloAddFlagLatencyThreshold > supportedTargetMaximum) {
+			handleErr(fmt.Errorf("Latency threshold must be between %v and %v seconds", supportedTargetMinimum, supportedTargetMaximum))
+		}
+		if sloAddFlagBurnRateAlert < 0 || (sloAddFlagBurnRateAlert > 0 && sloAddFlagBurnRateAlert < 1) {
+			handleErr(fmt.Errorf("Burn rate alert must be at least 1, or 0 to disable alerts"))
+		}
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading checks...")
+		checks, err := fetchChecks(url.Values{})
+		if err != nil {
+			handleErr(err)
+		}
+		channels, err := fetchChannels(url.Values{})
+		if err != nil {
+			handleErr(err)
+		}
+		spin.Stop()
+
+		if len(checks) == 0 {
+			handleErr(fmt.Errorf("Add a check first, SLOs are measured on checks"))
+		}
+		match, err = regexp.MatchString(validChecksIdentListPattern, strings.Join(sloAddFlagCheck, ","))
+		if err != nil {
+			handleErr(err)
+		} else if !match {
+			var options = []string{}
+			for _, c := range checks {
+				options = append(options, c.Ident+" "+c.Identity())
+			}
+			prompt := &survey.MultiSelect{
+				Message:  "Checks the objective applies to:",
+				Options:  options,
+				PageSize: 9,
+			}
+			err = survey.AskOne(prompt, &sloAddFlagCheck, survey.WithValidator(survey.MinItems(1)))
+			if err != nil {
+				handleErr(err)
+			}
+		} else if strings.Join(sloAddFlagCheck, ",") == "all" {
+			sloAddFlagCheck = []string{}
+			for _, c := range checks {
+				sloAddFlagCheck = append(sloAddFlagCheck, c.Ident)
+			}
+		}
+		for i, c := range sloAddFlagCheck {
+			sloAddFlagCheck[i] = strings.Split(c, " ")[0]
+		}
+
+		if sloAddFlagBurnRateAlert > 0 && len(channels) > 0 {
+			match, err = regexp.MatchString(validChannelsIdentListPattern, strings.Join(sloAddFlagAttach, ","))
+			if err != nil {
+				handleErr(err)
+			} else if !match {
+				var options = []string{}
+				for _, c := range channels {
+					options = append(options, c.Ident+" "+c.Type+" "+c.Identity())
+				}
+				prompt := &survey.MultiSelect{
+					Message:  "Channels to notify about burn rate alerts (optional):",
+					Options:  options,
+					PageSize: 9,
+				}
+				err = survey.AskOne(prompt, &sloAddFlagAttach)
+				if err != nil {
+					handleErr(err)
+				}
+			} else if strings.Join(sloAddFlagAttach, ",") == "all" {
+				sloAddFlagAttach = []string{}
+				for _, c := range channels {
+					sloAddFlagAttach = append(sloAddFlagAttach, c.Ident)
+				}
+			}
+			for i, c := range sloAddFlagAttach {
+				sloAddFlagAttach[i] = strings.Split(c, " ")[0]
+			}
+		} else if len(sloAddFlagAttach) > 0 {
+			handleErr(fmt.Errorf("--attach requires --burn_rate_alert"))
+		}
+
+		slo := SLO{
+			Name:             sloAddFlagName,
+			Indicator:        sloAddFlagIndicator,
+			Objective:        sloAddFlagObjective,
+			Window:           sloAddFlagWindow,
+			LatencyThreshold: sloAddFlagLatencyThreshold,
+			BurnRateAlert:    sloAddFlagBurnRateAlert,
+			Checks:           sloAddFlagCheck,
+			Channels:         sloAddFlagAttach,
+		}
+		postData, err := json.Marshal(slo)
+		if err != nil {
+			handleErr(err)
+		}
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" saving SLO...")
+		respData, err := util.BinocsAPI("/slos", http.MethodPost, postData)
+		if err != nil {
+			spin.Stop()
+			handleErr(err)
+		}
+		err = json.Unmarshal(respData, &slo)
+		if err != nil {
+			spin.Stop()
+			handleErr(err)
+		}
+		spin.Stop()
+		if slo.ID == 0 {
+			handleErr(fmt.Errorf("Error adding SLO"))
+		}
+		fmt.Println("SLO " + `"` + slo.Name + `"` + " [" + slo.Ident + "] added successfully")
+	},
+}
+
+var sloDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete service level objective(s)",
+	Long: `
+Delete service level objective(s).
+
+This command is interactive and asks for confirmation.
+`,
+	Aliases:           []string{"del", "rm"},
+	Args:              cobra.MinimumNArgs(1),
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		for _, arg := range args {
+			slo, err := fetchSLO(arg)
+			if err != nil {
+				handleWarn("Error loading SLO " + arg)
+				continue
+			}
+			prompt := &survey.Confirm{
+				Message: "Delete SLO " + slo.Name + " (" + formatSLOObjective(&slo) + ")?",
+			}
+			var yes bool
+			err = survey.AskOne(prompt, &yes)
+			if err != nil {
+				continue
+			}
+			if yes {
+				_, err = util.BinocsAPI("/slos/"+arg, http.MethodDelete, []byte{})
+				if err != nil {
+					handleWarn("Error deleting SLO " + arg)
+					continue
+				} else {
+					fmt.Println("SLO successfully deleted")
+				}
+			} else {
+				fmt.Println("OK, skipping")
+			}
+		}
+	},
+}
+
+var sloInspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "View SLO attainment, error budget and burn rates",
+	Long: `
+View SLO attainment, remaining error budget and burn rates over 1 hour, 6 hour and 3 day windows.
+
+A burn rate of 1 spends the error budget exactly over the SLO window; higher values exhaust it sooner.
+`,
+	Aliases:           []string{"view", "show", "info"},
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		match, err := regexp.MatchString(validSLOIdentPattern, args[0])
+		if err != nil || !match {
+			handleErr(fmt.Errorf("Provided SLO identifier is invalid"))
+		}
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading SLO...")
+		slo, err := fetchSLO(args[0])
+		if err != nil {
+			handleErr(err)
+		}
+		checks, err := fetchChecks(url.Values{})
+		if err != nil {
+			handleErr(err)
+		}
+
+		// Table "main"
+
+		latencyLine := ""
+		if slo.Indicator == sloIndicatorLatency {
+			if slo.LatencyThreshold > 0 {
+				latencyLine = colorBold.Sprint(`Latency threshold: `) + fmt.Sprintf("%.3f s", slo.LatencyThreshold) + "\n"
+			} else {
+				latencyLine = colorBold.Sprint(`Latency threshold: `) + "target response time of each check" + "\n"
+			}
+		}
+		burnRateAlert := colorFaint.Sprint("off")
+		if slo.BurnRateAlert > 0 {
+			burnRateAlert = fmt.Sprintf("%.1f×, %d channel(s)", slo.BurnRateAlert, len(slo.Channels))
+		}
+
+		tableMainSLOCellContent := colorBold.Sprint(`ID: `) + slo.Ident + "\n" +
+			colorBold.Sprint(`Name: `) + slo.Name + "\n" +
+			colorBold.Sprint(`Indicator: `) + slo.Indicator + "\n" +
+			colorBold.Sprint(`Objective: `) + formatSLOObjective(&slo) + "\n" +
+			latencyLine +
+			colorBold.Sprint(`Burn rate alert: `) + burnRateAlert
+
+		budgetTotal := sloErrorBudgetMinutes(&slo)
+		budgetRemaining, budgetErr := sloRemainingErrorBudgetMinutes(&slo)
+		tableMainStatusCellContent := colorBold.Sprint(`Attainment: `) + formatSLOAttainment(slo.Status.Attainment, slo.Objective) + "\n" +
+			colorBold.Sprint(`Error budget: `) + fmt.Sprintf("%.0f min", budgetTotal) + "\n" +
+			colorBold.Sprint(`Remaining: `) + formatErrorBudget(budgetRemaining, budgetTotal, budgetErr)
+
+		tableMainBurnRateCellContent := colorBold.Sprint(`1 hour: `) + formatBurnRate(slo.Status.Attainment1h, &slo) + "\n" +
+			colorBold.Sprint(`6 hours: `) + formatBurnRate(slo.Status.Attainment6h, &slo) + "\n" +
+			colorBold.Sprint(`3 days: `) + formatBurnRate(slo.Status.Attainment3d, &slo)
+
+		var tableMainChecksCellContent []string
+		for _, c := range checks {
+			if util.StringInSlice(c.Ident, slo.Checks) {
+				tableMainChecksCellContent = append(tableMainChecksCellContent, c.Ident+" - "+c.Identity())
+			}
+		}
+		if len(tableMainChecksCellContent) == 0 {
+			tableMainChecksCellContent = []string{"-"}
+		}
+
+		columnDefinitions := []tableColumnDefinition{
+			{
+				Header:    "SLO",
+				Priority:  1,
+				Alignment: tablewriter.ALIGN_LEFT,
+			},
+			{
+				Header:    "STATUS (" + strconv.Itoa(slo.Window) + " DAYS)",
+				Priority:  1,
+				Alignment: tablewriter.ALIGN_LEFT,
+			},
+			{
+				Header:    "BURN RATE",
+				Priority:  1,
+				Alignment: tablewriter.ALIGN_LEFT,
+			},
+			{
+				Header:    "CHECKS",
+				Priority:  2,
+				Alignment: tablewriter.ALIGN_LEFT,
+			},
+		}
+
+		var tableData [][]string
+		tableData = append(tableData, []string{tableMainSLOCellContent, tableMainStatusCellContent, tableMainBurnRateCellContent, strings.Join(tableMainChecksCellContent, "\n")})
+		table := composeTable(tableData, columnDefinitions)
+
+		spin.Stop()
+		table.Render()
+	},
+}
+
+var sloListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all service level objectives",
+	Long: `
+List all service level objectives with their attainment, remaining error budget and burn rates.
+`,
+	Aliases:           []string{"ls"},
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading SLOs...")
+
+		slos, err := fetchSLOs(url.Values{})
+		if err != nil {
+			handleErr(err)
+		}
+		sort.Slice(slos, func(i, j int) bool {
+			return slos[i].Name < slos[j].Name
+		})
+
+		var tableData [][]string
+		for _, v := range slos {
+			budgetTotal := sloErrorBudgetMinutes(&v)
+			budgetRemaining, budgetErr := sloRemainingErrorBudgetMinutes(&v)
+			tableRow := []string{
+				colorBold.Sprint(v.Ident),
+				v.Name,
+				v.Indicator,
+				formatSLOObjective(&v),
+				strconv.Itoa(len(v.Checks)),
+				formatSLOAttainment(v.Status.Attainment, v.Objective),
+				formatErrorBudget(budgetRemaining, budgetTotal, budgetErr),
+				formatBurnRate(v.Status.Attainment1h, &v),
+				formatBurnRate(v.Status.Attainment6h, &v),
+				formatBurnRate(v.Status.Attainment3d, &v),
+			}
+			tableData = append(tableData, tableRow)
+		}
+
+		columnDefinitions := []tableColumnDefinition{
+			{
+				Header:    "ID",
+				Priority:  1,
+				Alignment: tablewriter.ALIGN_LEFT,
+			},
+			{
+				Header:    "NAME",
+				Priority:  1,
+				Alignment: tablewriter.ALIGN_LEFT,
+			},
+			{
+				Header:    "INDICATOR",
+				Priority:  3,
+				Alignment: tablewriter.ALIGN_LEFT,
+			},
+			{
+				Header:    "OBJECTIVE",
+				Priority:  2,
+				Alignment: tablewriter.ALIGN_RIGHT,
+			},
+			{
+				Header:    "CHECKS",
+				Priority:  3,
+				Alignment: tablewriter.ALIGN_RIGHT,
+			},
+			{
+				Header:    "ATTAINMENT",
+				Priority:  1,
+				Alignment: tablewriter.ALIGN_RIGHT,
+			},
+			{
+				Header:    "BUDGET LEFT",
+				Priority:  1,
+				Alignment: tablewriter.ALIGN_RIGHT,
+			},
+			{
+				Header:    "BURN 1H",
+				Priority:  2,
+				Alignment: tablewriter.ALIGN_RIGHT,
+			},
+			{
+				Header:    "BURN 6H",
+				Priority:  2,
+				Alignment: tablewriter.ALIGN_RIGHT,
+			},
+			{
+				Header:    "BURN 3D",
+				Priority:  3,
+				Alignment: tablewriter.ALIGN_RIGHT,
+			},
+		}
+
+		table := composeTable(tableData, columnDefinitions)
+		spin.Stop()
+		table.Render()
+	},
+}
+
+func fetchSLO(ident string) (SLO, error) {
+	var slo SLO
+	respData, err := util.BinocsAPI("/slos/"+ident, http.MethodGet, []byte{})
+	if err != nil {
+		return slo, err
+	}
+	err = json.Unmarshal(respData, &slo)
+	if err != nil {
+		return slo, err
+	}
+	return slo, nil
+}
+
+func fetchSLOs(urlValues url.Values) ([]SLO, error) {
+	var slos []SLO
+	respData, err := util.BinocsAPI("/slos?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return slos, err
+	}
+	slos = make([]SLO, 0)
+	decoder := json.NewDecoder(bytes.NewBuffer(respData))
+	err = decoder.Decode(&slos)
+	if err != nil {
+		return slos, err
+	}
+	return slos, nil
+}
+
+// sloErrorBudgetMinutes is the total unreliability allowed over the SLO window, in minutes
+func sloErrorBudgetMinutes(slo *SLO) float64 {
+	return float64(slo.Window) * 24 * 60 * (100 - slo.Objective) / 100
+}
+
+// sloRemainingErrorBudgetMinutes is the error budget not spent yet; negative once the objective is missed
+func sloRemainingErrorBudgetMinutes(slo *SLO) (float64, error) {
+	attainment, err := strconv.ParseFloat(slo.Status.Attainment, 64)
+	if err != nil {
+		return 0, err
+	}
+	return float64(slo.Window) * 24 * 60 * (attainment - slo.Objective) / 100, nil
+}
+
+// sloBurnRate is how many times faster than sustainable the error budget is spent at the given attainment
+func sloBurnRate(attainment string, objective float64) (float64, error) {
+	attainmentFloat, err := strconv.ParseFloat(attainment, 64)
+	if err != nil {
+		return 0, err
+	}
+	return (100 - attainmentFloat) / (100 - objective), nil
+}
+
+func formatSLOObjective(slo *SLO) string {
+	return strconv.FormatFloat(slo.Objective, 'f', -1, 64) + " % / " + strconv.Itoa(slo.Window) + " d"
+}
+
+func formatSLOAttainment(attainment string, objective float64) string {
+	attainmentFloat, err := strconv.ParseFloat(attainment, 64)
+	if err != nil {
+		return colorFaint.Sprint("n/a")
+	}
+	if attainmentFloat >= objective {
+		return color.GreenString("%v %%", attainment)
+	}
+	return color.RedString("%v %%", attainment)
+}
+
+func formatErrorBudget(remaining, total float64, err error) string {
+	if err != nil || total == 0 {
+		return colorFaint.Sprint("n/a")
+	}
+	snippet := fmt.Sprintf("%.0f min (%.0f %%)", remaining, 100*remaining/total)
+	if remaining <= 0 {
+		return color.RedString(snippet)
+	}
+	if remaining < total/4 {
+		return color.YellowString(snippet)
+	}
+	return color.GreenString(snippet)
+}
+
+func formatBurnRate(attainment string, slo *SLO) string {
+	burnRate, err := sloBurnRate(attainment, slo.Objective)
+	if err != nil {
+		return colorFaint.Sprint("n/a")
+	}
+	snippet := fmt.Sprintf("%.2f×", burnRate)
+	if slo.BurnRateAlert > 0 && burnRate >= slo.BurnRateAlert {
+		return color.RedString(snippet)
+	}
+	if burnRate > 1 {
+		return color.YellowString(snippet)
+	}
+	return color.GreenString(snippet)
+}
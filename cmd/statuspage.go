@@ -0,0 +1,514 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	htmltemplate "html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	statusPageDefaultHistoryDays  = 90
+	statusPageDefaultMaxIncidents = 20
+	statusPageOperational         = "operational"
+	statusPageDegraded            = "degraded"
+	statusPageOutage              = "outage"
+)
+
+// StatusPageConfig is read from the --config YAML file
+type StatusPageConfig struct {
+	Title        string                  `yaml:"title"`
+	Description  string                  `yaml:"description"`
+	BaseURL      string                  `yaml:"base_url"`
+	Timezone     string                  `yaml:"timezone"`
+	HistoryDays  int                     `yaml:"history_days"`
+	MaxIncidents int                     `yaml:"max_incidents"`
+	Checks       []StatusPageCheckConfig `yaml:"checks"`
+}
+
+// StatusPageCheckConfig selects a check to publish; Name overrides the check name on the page
+type StatusPageCheckConfig struct {
+	Ident string `yaml:"ident"`
+	Name  string `yaml:"name"`
+}
+
+// StatusPageSummary is published as summary.json
+type StatusPageSummary struct {
+	Title     string                   `json:"title"`
+	Status    string                   `json:"status"`
+	Updated   string                   `json:"updated"`
+	Checks    []StatusPageCheckSummary `json:"checks"`
+	Incidents []StatusPageIncident     `json:"incidents"`
+}
+
+// StatusPageCheckSummary is the published state of a single check
+type StatusPageCheckSummary struct {
+	Ident       string                `json:"ident"`
+	Name        string                `json:"name"`
+	Status      string                `json:"status"`
+	Uptime      string                `json:"uptime"`
+	DailyUptime []DailyUptimeResponse `json:"daily_uptime"`
+	UptimeChart string                `json:"-"`
+}
+
+// StatusPageIncident is the published part of an incident
+type StatusPageIncident struct {
+	Ident    string `json:"ident"`
+	Check    string `json:"check"`
+	State    string `json:"state"`
+	Opened   string `json:"opened"`
+	Closed   string `json:"closed,omitempty"`
+	Duration string `json:"duration"`
+	Note     string `json:"note,omitempty"`
+	updated  time.Time
+}
+
+// `statuspage build` flags
+var (
+	statusPageBuildFlagConfig string
+	statusPageBuildFlagOut    string
+)
+
+func init() {
+	rootCmd.AddCommand(statusPageCmd)
+	statusPageCmd.AddCommand(statusPageBuildCmd)
+
+	statusPageBuildCmd.Flags().StringVar(&statusPageBuildFlagConfig, "config", "statuspage.yaml", "status page configuration file")
+	statusPageBuildCmd.Flags().StringVar(&statusPageBuildFlagOut, "out", "public", "directory to write the status page to")
+}
+
+var statusPageCmd = &cobra.Command{
+	Use:   "statuspage",
+	Short: "Generate public status page",
+	Long: `
+Generate public status page.
+`,
+	DisableAutoGenTag: true,
+}
+
+var statusPageBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build static status page",
+	Long: `
+Build a static status page of selected checks, ready to publish to any static host.
+
+The page shows current status, daily uptime bars and active and recent incidents with their notes.
+An Atom feed of incidents (feed.atom) and a JSON summary (summary.json) are generated alongside.
+
+The configuration file looks like this:
+
+  title: Example Status
+  description: Current status of Example services
+  base_url: https://status.example.com/
+  timezone: Europe/Prague   # optional, default is your timezone
+  history_days: 90          # optional
+  max_incidents: 20         # optional
+  checks:
+    - ident: abcdef1
+      name: API
+    - ident: 1234567
+
+Only files whose content changed are rewritten, and the output only depends on the monitoring data,
+so the build can run repeatedly, e.g. from cron.
+`,
+	Example:           `  binocs statuspage build --config statuspage.yaml --out ./public`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		config, err := loadStatusPageConfig(statusPageBuildFlagConfig)
+		if err != nil {
+			handleErr(err)
+		}
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading status page data...")
+
+		tz := time.UTC
+		if len(config.Timezone) > 0 {
+			tz, err = time.LoadLocation(config.Timezone)
+			if err != nil {
+				spin.Stop()
+				handleErr(fmt.Errorf("Invalid timezone provided in %s: %s", statusPageBuildFlagConfig, config.Timezone))
+			}
+		} else {
+			user, err := fetchUser()
+			if err != nil {
+				handleErr(err)
+			}
+			tz = userLocation(&user)
+		}
+
+		summary, err := composeStatusPageSummary(config, tz)
+		if err != nil {
+			handleErr(err)
+		}
+
+		files := map[string][]byte{}
+		files["index.html"], err = renderStatusPage(config, summary)
+		if err != nil {
+			handleErr(err)
+		}
+		files["style.css"] = []byte(statusPageCSS)
+		files["feed.atom"], err = renderStatusPageFeed(config, summary)
+		if err != nil {
+			handleErr(err)
+		}
+		files["summary.json"], err = json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			handleErr(err)
+		}
+
+		err = os.MkdirAll(statusPageBuildFlagOut, 0755)
+		if err != nil {
+			handleErr(err)
+		}
+		var names []string
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var written int
+		for _, name := range names {
+			changed, err := writeFileIfChanged(filepath.Join(statusPageBuildFlagOut, name), files[name])
+			if err != nil {
+				handleErr(err)
+			}
+			if changed {
+				written++
+			}
+		}
+		spin.Stop()
+		fmt.Printf("Status page built in %s: %d file(s) updated, %d unchanged\n", statusPageBuildFlagOut, written, len(files)-written)
+	},
+}
+
+func loadStatusPageConfig(path string) (StatusPageConfig, error) {
+	var config StatusPageConfig
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	err = yaml.Unmarshal(content, &config)
+	if err != nil {
+		return config, fmt.Errorf("Invalid status page configuration in %s: %v", path, err)
+	}
+	if len(config.Checks) == 0 {
+		return config, fmt.Errorf("No checks configured in %s", path)
+	}
+	for _, c := range config.Checks {
+		match, err := regexp.MatchString(validCheckIdentPattern, c.Ident)
+		if err != nil || !match {
+			return config, fmt.Errorf("Invalid check identifier in %s: %s", path, c.Ident)
+		}
+	}
+	if len(config.Title) == 0 {
+		config.Title = "Status"
+	}
+	if config.HistoryDays <= 0 {
+		config.HistoryDays = statusPageDefaultHistoryDays
+	}
+	if config.MaxIncidents <= 0 {
+		config.MaxIncidents = statusPageDefaultMaxIncidents
+	}
+	return config, nil
+}
+
+// composeStatusPageSummary loads everything the page shows; days end at midnight in tz
+func composeStatusPageSummary(config StatusPageConfig, tz *time.Location) (StatusPageSummary, error) {
+	summary := StatusPageSummary{
+		Title:     config.Title,
+		Status:    statusPageOperational,
+		Checks:    []StatusPageCheckSummary{},
+		Incidents: []StatusPageIncident{},
+	}
+	now := time.Now().In(tz)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
+	tr := timeRange{
+		From:   today.AddDate(0, 0, -config.HistoryDays+1),
+		To:     today.AddDate(0, 0, 1),
+		Points: config.HistoryDays,
+	}
+	var updated time.Time
+
+	for _, cc := range config.Checks {
+		spin.Suffix = colorFaint.Sprintf(" loading status of check %s...", cc.Ident)
+		respData, err := util.BinocsAPI("/checks/"+cc.Ident, http.MethodGet, []byte{})
+		if err != nil {
+			return summary, err
+		}
+		var check Check
+		err = json.Unmarshal(respData, &check)
+		if err != nil {
+			return summary, err
+		}
+		name := cc.Name
+		if len(name) == 0 {
+			name = check.Name
+		}
+		if len(name) == 0 {
+			name = check.Resource
+		}
+
+		dailyUptimeURLValues := url.Values{}
+		dailyUptimeURLValues.Set("from", tr.From.Format(calendarDateLayout))
+		dailyUptimeURLValues.Set("to", today.Format(calendarDateLayout))
+		dailyUptimeURLValues.Set("timezone", tz.String())
+		days, err := fetchDailyUptime(check.Ident, dailyUptimeURLValues)
+		if err != nil {
+			return summary, err
+		}
+
+		metricsURLValues := url.Values{}
+		metricsURLValues.Set("from", tr.From.UTC().Format(time.RFC3339))
+		metricsURLValues.Set("to", tr.To.UTC().Format(time.RFC3339))
+		metrics, err := fetchMetrics(check.Ident, &metricsURLValues)
+		if err != nil {
+			return summary, err
+		}
+
+		status := statusPageCheckStatus(check.LastStatus)
+		summary.Checks = append(summary.Checks, StatusPageCheckSummary{
+			Ident:       check.Ident,
+			Name:        name,
+			Status:      status,
+			Uptime:      metrics.Uptime,
+			DailyUptime: days,
+			UptimeChart: drawSVGUptimeChart(days, tr),
+		})
+		if status == statusPageOutage || (status == statusPageDegraded && summary.Status == statusPageOperational) {
+			summary.Status = status
+		}
+
+		incidentsURLValues := url.Values{}
+		incidentsURLValues.Set("check", check.Ident)
+		incidentsURLValues.Set("from", tr.From.UTC().Format(time.RFC3339))
+		incidentsURLValues.Set("to", tr.To.UTC().Format(time.RFC3339))
+		incidents, err := fetchIncidents(incidentsURLValues)
+		if err != nil {
+			return summary, err
+		}
+		for _, v := range incidents {
+			if v.CheckIdent != check.Ident {
+				continue
+			}
+			si := StatusPageIncident{
+				Ident:    v.Ident,
+				Check:    name,
+				State:    v.IncidentState,
+				Opened:   v.Opened,
+				Closed:   v.Closed,
+				Duration: util.OutputDurationWithDays(v.Duration),
+				Note:     v.IncidentNote,
+			}
+			opened, err := time.Parse(statusHistoryTimeLayout, v.Opened)
+			if err == nil {
+				si.Opened = opened.In(tz).Format(reportTimeLayout)
+				si.updated = opened
+			}
+			closed, err := time.Parse(statusHistoryTimeLayout, v.Closed)
+			if err == nil && v.IncidentState != incidentStateOpen {
+				si.Closed = closed.In(tz).Format(reportTimeLayout)
+				si.updated = closed
+			}
+			if si.updated.After(updated) {
+				updated = si.updated
+			}
+			summary.Incidents = append(summary.Incidents, si)
+		}
+	}
+
+	sort.SliceStable(summary.Incidents, func(i, j int) bool {
+		a, b := summary.Incidents[i], summary.Incidents[j]
+		if (a.State == incidentStateOpen) != (b.State == incidentStateOpen) {
+			return a.State == incidentStateOpen
+		}
+		if !a.updated.Equal(b.updated) {
+			return a.updated.After(b.updated)
+		}
+		return a.Ident < b.Ident
+	})
+	if len(summary.Incidents) > config.MaxIncidents {
+		summary.Incidents = summary.Incidents[:config.MaxIncidents]
+	}
+	if updated.IsZero() {
+		updated = tr.From
+	}
+	summary.Updated = updated.UTC().Format(time.RFC3339)
+	return summary, nil
+}
+
+func statusPageCheckStatus(status int) string {
+	switch status {
+	case statusUp:
+		return statusPageOperational
+	case statusDown:
+		return statusPageOutage
+	}
+	return statusPageDegraded
+}
+
+func renderStatusPage(config StatusPageConfig, summary StatusPageSummary) ([]byte, error) {
+	tmpl, err := htmltemplate.New("statuspage").Funcs(htmltemplate.FuncMap{
+		"svg": func(s string) htmltemplate.HTML { return htmltemplate.HTML(s) },
+		"statusTitle": func(status string) string {
+			switch status {
+			case statusPageOperational:
+				return "Operational"
+			case statusPageOutage:
+				return "Outage"
+			}
+			return "Degraded"
+		},
+	}).Parse(statusPageHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, struct {
+		Config  StatusPageConfig
+		Summary StatusPageSummary
+	}{config, summary})
+	return buf.Bytes(), err
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Content string   `xml:"content"`
+}
+
+func renderStatusPageFeed(config StatusPageConfig, summary StatusPageSummary) ([]byte, error) {
+	feed := atomFeed{
+		Title:   config.Title,
+		ID:      "urn:binocs:statuspage:" + strings.ToLower(strings.ReplaceAll(config.Title, " ", "-")),
+		Updated: summary.Updated,
+		Link:    atomLink{Href: config.BaseURL},
+	}
+	if len(config.BaseURL) > 0 {
+		feed.ID = config.BaseURL
+	}
+	for _, v := range summary.Incidents {
+		state := "resolved"
+		if v.State == incidentStateOpen {
+			state = "ongoing"
+		}
+		content := fmt.Sprintf("Opened %s", v.Opened)
+		if len(v.Closed) > 0 {
+			content += fmt.Sprintf(", resolved %s after %s", v.Closed, v.Duration)
+		}
+		if len(v.Note) > 0 {
+			content += ". " + v.Note
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   v.Check + " incident " + state,
+			ID:      "urn:binocs:incident:" + v.Ident,
+			Updated: v.updated.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: config.BaseURL + "#incident-" + v.Ident},
+			Content: content,
+		})
+	}
+	content, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
+
+// writeFileIfChanged leaves files with the same content untouched, so that their modification time is kept
+func writeFileIfChanged(path string, content []byte) (bool, error) {
+	current, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(current, content) {
+		return false, nil
+	}
+	return true, os.WriteFile(path, content, 0644)
+}
+
+const statusPageHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ .Config.Title }}</title>
+<link rel="stylesheet" href="style.css">
+<link rel="alternate" type="application/atom+xml" title="{{ .Config.Title }}" href="feed.atom">
+</head>
+<body>
+<header>
+<h1>{{ .Config.Title }}</h1>
+{{ if .Config.Description }}<p class="muted">{{ .Config.Description }}</p>{{ end }}
+</header>
+<p class="banner {{ .Summary.Status }}">{{ if eq .Summary.Status "operational" }}All systems operational{{ else if eq .Summary.Status "outage" }}Some systems are down{{ else }}Some systems are degraded{{ end }}</p>
+<section>
+{{ range .Summary.Checks }}<div class="check">
+<div class="check-header"><span class="name">{{ .Name }}</span><span class="status {{ .Status }}">{{ statusTitle .Status }}</span></div>
+<div class="bars">{{ svg .UptimeChart }}</div>
+<div class="check-footer muted"><span>{{ $.Config.HistoryDays }} days ago</span><span>{{ if .Uptime }}{{ .Uptime }} % uptime{{ end }}</span><span>Today</span></div>
+</div>
+{{ end }}</section>
+<section>
+<h2>Incidents</h2>
+{{ range .Summary.Incidents }}<article id="incident-{{ .Ident }}" class="incident {{ .State }}">
+<h3>{{ .Check }}{{ if eq .State "open" }} <span class="status outage">Ongoing</span>{{ end }}</h3>
+<p class="muted">Opened {{ .Opened }}{{ if .Closed }}, resolved {{ .Closed }} after {{ .Duration }}{{ end }}</p>
+{{ if .Note }}<p>{{ .Note }}</p>{{ end }}
+</article>
+{{ else }}<p class="muted">No recent incidents.</p>
+{{ end }}</section>
+<footer class="muted"><a href="feed.atom">Atom feed</a> · <a href="summary.json">JSON</a></footer>
+</body>
+</html>
+`
+
+const statusPageCSS = `body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292f; max-width: 760px; margin: 2em auto; padding: 0 1em; }
+h1 { font-size: 1.8em; margin-bottom: 0.2em; }
+h2 { font-size: 1.3em; margin-top: 2em; }
+h3 { font-size: 1em; margin: 0 0 0.2em; }
+a { color: #0969da; }
+.muted { color: #57606a; font-size: 0.9em; }
+.banner { padding: 1em; border-radius: 6px; color: #fff; font-weight: 600; }
+.banner.operational { background: #2da44e; }
+.banner.degraded { background: #d4a72c; }
+.banner.outage { background: #cf222e; }
+.check { border: 1px solid #d0d7de; border-radius: 6px; padding: 1em; margin: 1em 0; }
+.check-header, .check-footer { display: flex; justify-content: space-between; }
+.check-header .name { font-weight: 600; }
+.status.operational { color: #2da44e; }
+.status.degraded { color: #d4a72c; }
+.status.outage { color: #cf222e; }
+.bars { margin: 0.6em 0; }
+.bars svg { width: 100%; height: 32px; }
+.incident { border-left: 3px solid #d0d7de; padding-left: 1em; margin: 1em 0; }
+.incident.open { border-color: #cf222e; }
+footer { margin-top: 3em; }
+`
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/automato-io/tablewriter"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const statusHistoryTimeLayout = "2006-01-02 15:04:05 -0700"
+
+// StatusSegment comes from the API as a JSON; an empty To means the status lasts until now
+type StatusSegment struct {
+	Status int    `json:"status"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// `check timeline` flags
+var (
+	checkTimelineFlagPeriod string
+	checkTimelineFlagFrom   string
+	checkTimelineFlagTo     string
+	checkTimelineFlagSince  string
+)
+
+// `timeline` flags
+var (
+	timelineFlagPeriod string
+	timelineFlagFrom   string
+	timelineFlagTo     string
+	timelineFlagSince  string
+	timelineFlagStatus string
+)
+
+// statusTimelineRank decides which status a timeline cell shows when several overlap it; outages win
+var statusTimelineRank = map[int]int{
+	statusUnknown:  0,
+	statusUp:       1,
+	statusStepUp:   2,
+	statusStepDown: 3,
+	statusDown:     4,
+}
+
+var statusTimelineOrder = []int{statusUp, statusStepUp, statusStepDown, statusDown, statusUnknown}
+
+func init() {
+	rootCmd.AddCommand(timelineCmd)
+	checkCmd.AddCommand(checkTimelineCmd)
+
+	checkTimelineCmd.Flags().StringVarP(&checkTimelineFlagPeriod, "period", "p", "", "display status timeline for specified period; default day")
+	checkTimelineCmd.Flags().StringVar(&checkTimelineFlagFrom, "from", "", "display status timeline from this time on, e.g. 2006-01-02T15:04, in your timezone")
+	checkTimelineCmd.Flags().StringVar(&checkTimelineFlagTo, "to", "", "display status timeline up to this time, e.g. 2006-01-02T15:04, in your timezone; default now")
+	checkTimelineCmd.Flags().StringVar(&checkTimelineFlagSince, "since", "", "display status timeline for a period ending now, e.g. 90m, 3h, 2d or 1w")
+
+	timelineCmd.Flags().StringVarP(&timelineFlagPeriod, "period", "p", "", "display status timelines for specified period; default day")
+	timelineCmd.Flags().StringVar(&timelineFlagFrom, "from", "", "display status timelines from this time on, e.g. 2006-01-02T15:04, in your timezone")
+	timelineCmd.Flags().StringVar(&timelineFlagTo, "to", "", "display status timelines up to this time, e.g. 2006-01-02T15:04, in your timezone; default now")
+	timelineCmd.Flags().StringVar(&timelineFlagSince, "since", "", "display status timelines for a period ending now, e.g. 90m, 3h, 2d or 1w")
+	timelineCmd.Flags().StringVarP(&timelineFlagStatus, "status", "s", "", "display only \"up\" or \"down\" checks, default \"all\"")
+}
+
+var checkTimelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "View check status transitions over time",
+	Long: `
+View UP, DOWN, tentative and unknown status segments of a check over time, with incidents listed under the bar.
+`,
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading status timeline...")
+
+		user, err := fetchUser()
+		if err != nil {
+			handleErr(err)
+		}
+		tr, err := parseTimeRange(checkTimelineFlagPeriod, checkTimelineFlagFrom, checkTimelineFlagTo, checkTimelineFlagSince, periodDay, userLocation(&user))
+		if err != nil {
+			spin.Stop()
+			handleErr(err)
+		}
+
+		respData, err := util.BinocsAPI("/checks/"+args[0], http.MethodGet, []byte{})
+		if err != nil {
+			handleErr(err)
+		}
+		var check Check
+		err = json.Unmarshal(respData, &check)
+		if err != nil {
+			handleErr(err)
+		}
+
+		table, err := composeStatusTimelineTable(&user, tr, []Check{check})
+		if err != nil {
+			handleErr(err)
+		}
+		spin.Stop()
+		table.Render()
+	},
+}
+
+var timelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "View status transitions of all checks over time",
+	Long: `
+View UP, DOWN, tentative and unknown status segments of all checks over time, stacked so that correlated outages line up.
+`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		urlValues := url.Values{}
+		timelineFlagStatus = strings.ToUpper(timelineFlagStatus)
+		if timelineFlagStatus == statusNameUp || timelineFlagStatus == statusNameDown {
+			urlValues.Set("status", timelineFlagStatus)
+		}
+
+		spin.Start()
+		defer spin.Stop()
+		spin.Suffix = colorFaint.Sprint(" loading status timelines...")
+
+		user, err := fetchUser()
+		if err != nil {
+			handleErr(err)
+		}
+		tr, err := parseTimeRange(timelineFlagPeriod, timelineFlagFrom, timelineFlagTo, timelineFlagSince, periodDay, userLocation(&user))
+		if err != nil {
+			spin.Stop()
+			handleErr(err)
+		}
+
+		checks, err := fetchChecks(urlValues)
+		if err != nil {
+			handleErr(err)
+		}
+		if len(checks) == 0 {
+			spin.Stop()
+			fmt.Println("No checks yet")
+			return
+		}
+		sort.Slice(checks, func(i, j int) bool {
+			return strings.ToLower(checks[i].Name) < strings.ToLower(checks[j].Name)
+		})
+
+		table, err := composeStatusTimelineTable(&user, tr, checks)
+		if err != nil {
+			handleErr(err)
+		}
+		spin.Stop()
+		table.Render()
+	},
+}
+
+// composeStatusTimelineTable draws a bar per check, the legend and time axis, and lists incidents of the range
+func composeStatusTimelineTable(user *User, tr timeRange, checks []Check) (*tablewriter.Table, error) {
+	urlValues := url.Values{}
+	tr.setURLValues(&urlValues, false)
+	tz := userLocation(user)
+
+	type checkSegments struct {
+		index    int
+		segments []StatusSegment
+		err      error
+	}
+	ch := make(chan checkSegments)
+	for i, c := range checks {
+		go func(i int, c Check) {
+			segments, err := fetchStatusHistory(c.Ident, urlValues)
+			ch <- checkSegments{i, segments, err}
+		}(i, c)
+	}
+	var err error
+	segments := make([][]StatusSegment, len(checks))
+	for range checks {
+		s := <-ch
+		if s.err != nil {
+			err = s.err
+			continue
+		}
+		segments[s.index] = s.segments
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	var labelWidth int
+	checkLabels := map[string]string{}
+	for _, c := range checks {
+		label := c.Ident
+		if len(c.Name) > 0 {
+			label = label + " " + util.Ellipsis(c.Name, 25)
+		}
+		labels = append(labels, label)
+		checkLabels[c.Ident] = label
+		if len([]rune(label)) > labelWidth {
+			labelWidth = len([]rune(label))
+		}
+	}
+
+	var bars []string
+	for i := range checks {
+		spacer := strings.Repeat(" ", labelWidth-len([]rune(labels[i])))
+		bars = append(bars, colorBold.Sprint(labels[i])+spacer+" "+drawStatusTimelineBar(segments[i], tr))
+	}
+	var legend []string
+	for _, s := range statusTimelineOrder {
+		legend = append(legend, formatStatusTimelineCell(s, 1)+" "+statusName[s])
+	}
+	leftMargin := strings.Repeat(" ", labelWidth+1)
+	chart := strings.Join(bars, "\n")
+	axis := drawTimeline(user, tr, leftMargin) + "\n\n" + leftMargin + strings.Join(legend, "  ")
+
+	incidentsURLValues := url.Values{}
+	tr.setURLValues(&incidentsURLValues, false)
+	if len(checks) == 1 {
+		incidentsURLValues.Set("check", checks[0].Ident)
+	}
+	incidents, err := fetchIncidents(incidentsURLValues)
+	if err != nil {
+		return nil, err
+	}
+	var incidentLines []string
+	for _, v := range incidents {
+		label, ok := checkLabels[v.CheckIdent]
+		if !ok {
+			continue
+		}
+		opened := v.Opened
+		if t, err := time.Parse(statusHistoryTimeLayout, v.Opened); err == nil {
+			opened = t.In(tz).Format("Jan 02 15:04")
+		}
+		duration := util.OutputDurationWithDays(v.Duration)
+		if v.IncidentState == incidentStateOpen {
+			duration = color.YellowString("open")
+		}
+		incidentLines = append(incidentLines, colorBold.Sprint(v.Ident)+"  "+label+strings.Repeat(" ", labelWidth-len([]rune(label)))+"  "+opened+"  "+duration)
+	}
+	if len(incidentLines) == 0 {
+		incidentLines = []string{colorFaint.Sprint("no incidents")}
+	}
+
+	columnDefinitions := []tableColumnDefinition{
+		{
+			Header:    "",
+			Priority:  1,
+			Alignment: tablewriter.ALIGN_LEFT,
+		},
+	}
+	var tableData [][]string
+	tableData = append(tableData, []string{drawChartTitle("STATUS TIMELINE", chart, tr.title())})
+	tableData = append(tableData, []string{chart})
+	tableData = append(tableData, []string{axis})
+	tableData = append(tableData, []string{colorBold.Sprint("INCIDENTS") + "\n" + strings.Join(incidentLines, "\n")})
+	table := composeTable(tableData, columnDefinitions)
+	table.SetRowLine(true)
+	return table, nil
+}
+
+// drawStatusTimelineBar splits the range into data points and shows the most severe status of each
+func drawStatusTimelineBar(segments []StatusSegment, tr timeRange) string {
+	dataPoints := tr.dataPoints()
+	step := tr.To.Sub(tr.From) / time.Duration(dataPoints)
+	cells := make([]int, dataPoints)
+	covered := make([]bool, dataPoints)
+	for _, s := range segments {
+		from, err := time.Parse(statusHistoryTimeLayout, s.From)
+		if err != nil {
+			continue
+		}
+		to := tr.To
+		if len(s.To) > 0 {
+			to, err = time.Parse(statusHistoryTimeLayout, s.To)
+			if err != nil {
+				continue
+			}
+		}
+		for i := 0; i < dataPoints; i++ {
+			cellFrom := tr.From.Add(time.Duration(i) * step)
+			cellTo := cellFrom.Add(step)
+			if from.Before(cellTo) && to.After(cellFrom) {
+				if !covered[i] || statusTimelineRank[s.Status] > statusTimelineRank[cells[i]] {
+					cells[i] = s.Status
+				}
+				covered[i] = true
+			}
+		}
+	}
+	var bar string
+	for i := 0; i < dataPoints; {
+		j := i
+		for j < dataPoints && cells[j] == cells[i] {
+			j++
+		}
+		bar = bar + formatStatusTimelineCell(cells[i], j-i)
+		i = j
+	}
+	return bar
+}
+
+func formatStatusTimelineCell(status int, width int) string {
+	switch status {
+	case statusUp:
+		return color.GreenString(strings.Repeat("█", width))
+	case statusStepUp:
+		return color.YellowString(strings.Repeat("▓", width))
+	case statusStepDown:
+		return color.YellowString(strings.Repeat("▒", width))
+	case statusDown:
+		return color.RedString(strings.Repeat("█", width))
+	}
+	return colorFaint.Sprint(strings.Repeat("░", width))
+}
+
+func fetchStatusHistory(ident string, urlValues url.Values) ([]StatusSegment, error) {
+	var segments []StatusSegment
+	respData, err := util.BinocsAPI("/checks/"+ident+"/status-history?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return segments, err
+	}
+	segments = make([]StatusSegment, 0)
+	decoder := json.NewDecoder(bytes.NewBuffer(respData))
+	err = decoder.Decode(&segments)
+	if err != nil {
+		return segments, err
+	}
+	return segments, nil
+}
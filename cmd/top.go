@@ -0,0 +1,505 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/fatih/color"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+)
+
+const (
+	topSortStatus         = "status"
+	topSortResponseTime   = "rt"
+	topSortApdex          = "apdex"
+	topSortErrors         = "errors"
+	topMinRefreshInterval = 2
+)
+
+var topSortKeys = []string{topSortStatus, topSortResponseTime, topSortApdex, topSortErrors}
+
+// statusSeverity ranks statuses from the worst, for `top --sort status`
+var statusSeverity = map[int]int{
+	statusDown:     4,
+	statusStepDown: 3,
+	statusUnknown:  2,
+	statusStepUp:   1,
+	statusUp:       0,
+}
+
+// `top` flags
+var (
+	topFlagSort            string
+	topFlagPeriod          string
+	topFlagRecent          int
+	topFlagRefreshInterval int
+)
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+
+	topCmd.Flags().StringVarP(&topFlagSort, "sort", "s", topSortStatus, "initial sort key: status, rt, apdex or errors")
+	topCmd.Flags().StringVarP(&topFlagPeriod, "period", "p", "day", "initial period of Apdex values and charts")
+	topCmd.Flags().IntVar(&topFlagRecent, "recent", 15, "how many last minutes response time, Apdex drop and error rate are measured over")
+	topCmd.Flags().IntVar(&topFlagRefreshInterval, "refresh_interval", 5, "how often to refresh data, in seconds")
+	topCmd.Flags().SortFlags = false
+}
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Live ranking of worst-performing checks",
+	Long: `
+Live ranking of worst-performing checks, refreshed every --refresh_interval seconds.
+
+Checks are sorted by one of:
+  status    down checks first, then tentative statuses
+  rt        mean response time over the last --recent minutes
+  apdex     Apdex drop over the last --recent minutes, compared to the whole period
+  errors    share of 4xx, 5xx and failed responses over the last --recent minutes
+
+Keys:
+  s         switch sort key
+  t         switch period of Apdex values and charts
+  ↑/↓, j/k  move in the list
+  r         refresh now
+  p         pause or resume refreshing
+  q         quit
+`,
+	Example:           `  binocs top --sort errors --recent 30`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		if !util.StringInSlice(topFlagSort, topSortKeys) {
+			handleErr(fmt.Errorf("Invalid sort key provided. Supported sort keys: %s", strings.Join(topSortKeys, ", ")))
+		}
+		match, err := regexp.MatchString(validPeriodPattern, topFlagPeriod)
+		if err != nil || !match {
+			handleErr(fmt.Errorf("Invalid period provided. Supported periods: hour, day, week, month"))
+		}
+		if time.Duration(topFlagRecent)*time.Minute < minRangeDuration {
+			handleErr(fmt.Errorf("Recent window must be at least %d minutes", int(minRangeDuration.Minutes())))
+		}
+		if time.Duration(topFlagRecent)*time.Minute > supportedPeriods[periodDay] {
+			handleErr(fmt.Errorf("Recent window must not be longer than a day"))
+		}
+		if topFlagRefreshInterval < topMinRefreshInterval {
+			handleErr(fmt.Errorf("Refresh interval must be at least %d seconds", topMinRefreshInterval))
+		}
+		spin.Disable()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		v := newTopView(topFlagSort, topFlagPeriod, time.Duration(topFlagRecent)*time.Minute, time.Duration(topFlagRefreshInterval)*time.Second)
+		err = v.run(ctx)
+		if err != nil {
+			handleErr(err)
+		}
+	},
+}
+
+// topEntry is a ranked check with its metrics over the period and over the recent window
+type topEntry struct {
+	check     Check
+	metrics   MetricsResponse
+	apdex     []ApdexResponse
+	recent    MetricsResponse
+	errorRate float64
+}
+
+// rank is the value checks are sorted by, descending; checks without data come last
+func (e topEntry) rank(sortKey string) float64 {
+	switch sortKey {
+	case topSortResponseTime:
+		if v, err := strconv.ParseFloat(e.recent.MRT, 64); err == nil {
+			return v
+		}
+	case topSortApdex:
+		period, err1 := strconv.ParseFloat(e.metrics.Apdex, 64)
+		recent, err2 := strconv.ParseFloat(e.recent.Apdex, 64)
+		if err1 == nil && err2 == nil {
+			return period - recent
+		}
+	case topSortErrors:
+		return e.errorRate
+	default:
+		return float64(statusSeverity[e.check.LastStatus])
+	}
+	return math.Inf(-1)
+}
+
+// topView is the state of `binocs top`; data fields are guarded by the mutex,
+// widgets are only touched from the tview event loop
+type topView struct {
+	sync.Mutex
+	sortKey       string
+	period        string
+	recent        time.Duration
+	interval      time.Duration
+	paused        bool
+	user          User
+	entries       []topEntry
+	openIncidents int
+	refreshed     time.Time
+	err           error
+	refreshNow    chan struct{}
+
+	app     *tview.Application
+	summary *tview.TextView
+	table   *tview.Table
+	status  *tview.TextView
+}
+
+func newTopView(sortKey, period string, recent, interval time.Duration) *topView {
+	v := &topView{
+		sortKey:    sortKey,
+		period:     period,
+		recent:     recent,
+		interval:   interval,
+		refreshNow: make(chan struct{}, 1),
+	}
+	tview.Styles.PrimitiveBackgroundColor = tcell.ColorDefault
+	tview.Styles.ContrastBackgroundColor = tcell.ColorDefault
+	tview.Styles.PrimaryTextColor = tcell.ColorDefault
+
+	v.app = tview.NewApplication()
+	v.summary = tview.NewTextView().SetDynamicColors(true)
+	v.table = newDashboardTable(" TOP ")
+	v.status = tview.NewTextView().SetDynamicColors(true)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.summary, 1, 0, false).
+		AddItem(v.table, 0, 1, true).
+		AddItem(v.status, 1, 0, false)
+	v.app.SetRoot(layout, true).SetFocus(v.table)
+	v.app.SetInputCapture(v.handleKey)
+	v.render()
+	return v
+}
+
+func (v *topView) run(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		v.app.Stop()
+	}()
+	go v.refreshLoop(ctx)
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				v.app.QueueUpdateDraw(v.renderStatus)
+			}
+		}
+	}()
+	return v.app.Run()
+}
+
+func (v *topView) refreshLoop(ctx context.Context) {
+	v.refresh()
+	ticker := time.NewTicker(v.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			v.Lock()
+			paused := v.paused
+			v.Unlock()
+			if !paused {
+				v.refresh()
+			}
+		case <-v.refreshNow:
+			v.refresh()
+			ticker.Reset(v.interval)
+		}
+	}
+}
+
+func (v *topView) requestRefresh() {
+	select {
+	case v.refreshNow <- struct{}{}:
+	default:
+	}
+}
+
+// refresh loads the ranking data; on failure the previous data is kept and the error is shown in the footer
+func (v *topView) refresh() {
+	v.Lock()
+	period, recent := v.period, v.recent
+	v.Unlock()
+	user, entries, openIncidents, err := loadTopData(period, recent)
+	v.Lock()
+	v.err = err
+	if err == nil {
+		v.user, v.entries, v.openIncidents = user, entries, openIncidents
+		v.refreshed = time.Now()
+	}
+	v.Unlock()
+	v.app.QueueUpdateDraw(v.render)
+}
+
+// loadTopData loads the user, checks and open incidents, then the check list data of each check over the period,
+// and its metrics and response codes over the recent window, with at most uiMetricsConcurrency checks at once
+func loadTopData(period string, recent time.Duration) (User, []topEntry, int, error) {
+	user, err := fetchUser()
+	if err != nil {
+		return user, nil, 0, err
+	}
+	checks, err := fetchChecks(url.Values{})
+	if err != nil {
+		return user, nil, 0, err
+	}
+	incidents, err := fetchIncidents(url.Values{"state": []string{incidentStateOpen}})
+	if err != nil {
+		return user, nil, 0, err
+	}
+	var openIncidents int
+	for _, i := range incidents {
+		if i.IncidentState == incidentStateOpen {
+			openIncidents++
+		}
+	}
+
+	periodURLValues := url.Values{}
+	timeRange{Period: period}.setURLValues(&periodURLValues, true)
+	now := time.Now()
+	recentRange := timeRange{From: now.Add(-recent), To: now}
+	recentURLValues := url.Values{}
+	recentRange.setURLValues(&recentURLValues, false)
+	recentAggregatedURLValues := url.Values{}
+	recentRange.setURLValues(&recentAggregatedURLValues, true)
+
+	entries := make([]topEntry, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	queue := make(chan int)
+	for i := 0; i < uiMetricsConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				e, err := loadTopEntry(checks[j], &periodURLValues, &recentURLValues, &recentAggregatedURLValues)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				entries[j] = e
+				mu.Unlock()
+			}
+		}()
+	}
+	for j := range checks {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+	return user, entries, openIncidents, firstErr
+}
+
+func loadTopEntry(check Check, periodURLValues, recentURLValues, recentAggregatedURLValues *url.Values) (topEntry, error) {
+	e := topEntry{check: check, errorRate: math.Inf(-1)}
+	var err error
+	e.metrics, e.apdex, err = fetchCheckListData(check.Ident, periodURLValues)
+	if err != nil {
+		return e, err
+	}
+	e.recent, err = fetchMetrics(check.Ident, recentURLValues)
+	if err != nil {
+		return e, err
+	}
+	responseCodes, err := fetchResponseCodes(check.Ident, recentAggregatedURLValues)
+	if err != nil {
+		return e, err
+	}
+	var total, errors int
+	for _, rc := range responseCodes {
+		total += rc.Xx1 + rc.Xx2 + rc.Xx3 + rc.Xx4 + rc.Xx5 + rc.Err
+		errors += rc.Xx4 + rc.Xx5 + rc.Err
+	}
+	if total > 0 {
+		e.errorRate = 100 * float64(errors) / float64(total)
+	}
+	return e, nil
+}
+
+func (v *topView) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune {
+		return event
+	}
+	switch event.Rune() {
+	case 'q':
+		v.app.Stop()
+	case 's':
+		v.Lock()
+		for i, k := range topSortKeys {
+			if k == v.sortKey {
+				v.sortKey = topSortKeys[(i+1)%len(topSortKeys)]
+				break
+			}
+		}
+		v.Unlock()
+		v.render()
+	case 't':
+		v.Lock()
+		for i, p := range uiPeriods {
+			if p == v.period {
+				v.period = uiPeriods[(i+1)%len(uiPeriods)]
+				break
+			}
+		}
+		v.Unlock()
+		v.render()
+		v.requestRefresh()
+	case 'r':
+		v.requestRefresh()
+	case 'p':
+		v.Lock()
+		v.paused = !v.paused
+		v.Unlock()
+		v.renderStatus()
+	default:
+		return event
+	}
+	return nil
+}
+
+func (v *topView) render() {
+	v.renderSummary()
+	v.renderTable()
+	v.renderStatus()
+}
+
+func (v *topView) renderSummary() {
+	v.Lock()
+	defer v.Unlock()
+	var up, down, unknown int
+	for _, e := range v.entries {
+		switch e.check.LastStatus {
+		case statusUp, statusStepUp:
+			up++
+		case statusDown, statusStepDown:
+			down++
+		default:
+			unknown++
+		}
+	}
+	parts := []string{fmt.Sprintf("[green]UP %d[-]", up), fmt.Sprintf("[red]DOWN %d[-]", down)}
+	if unknown > 0 {
+		parts = append(parts, fmt.Sprintf("[yellow]UNKNOWN %d[-]", unknown))
+	}
+	parts = append(parts,
+		fmt.Sprintf("open incidents %d", v.openIncidents),
+		fmt.Sprintf("credits %d", v.user.CreditBalance),
+		"[::d]period: "+v.period+" · sort: "+v.sortKey+"[::-]",
+	)
+	if v.refreshed.IsZero() {
+		parts = parts[len(parts)-1:]
+	}
+	v.summary.SetText(strings.Join(parts, " · "))
+}
+
+func (v *topView) renderTable() {
+	v.Lock()
+	entries := append([]topEntry{}, v.entries...)
+	sortKey, period, recent := v.sortKey, v.period, v.recent
+	zeroCredits := v.user.CreditBalance == 0
+	v.Unlock()
+	sort.SliceStable(entries, func(i, j int) bool {
+		ri, rj := entries[i].rank(sortKey), entries[j].rank(sortKey)
+		if ri != rj {
+			return ri > rj
+		}
+		return strings.ToLower(entries[i].check.Name) < strings.ToLower(entries[j].check.Name)
+	})
+	var rows [][]string
+	var idents []string
+	for _, e := range entries {
+		c := e.check
+		status := formatStatus(&c)
+		lastStatusCode := regexp.MustCompile(`^[1-5]{1}[0-9]{2}`).FindString(c.LastStatusCode)
+		if lastStatusCode == "" {
+			lastStatusCode = "-"
+		}
+		errorRate := colorFaint.Sprint("n/a")
+		if !math.IsInf(e.errorRate, -1) {
+			errorRate = strconv.FormatFloat(e.errorRate, 'f', 1, 64) + " %"
+			if e.errorRate > 0 {
+				errorRate = color.RedString(errorRate)
+			}
+		}
+		rt, apdex := formatMRT(e.recent.MRT), formatApdex(e.metrics.Apdex)
+		apdexDelta := formatMetricDelta(e.recent.Apdex, e.metrics.Apdex, 2, "", true)
+		apdexChart := drawCompactApdexChart(e.apdex, e.metrics.Apdex)
+		if e.metrics.Apdex == "" {
+			apdexChart = ""
+		}
+		if zeroCredits {
+			status = color.YellowString(statusName[statusUnknown])
+			lastStatusCode, rt, errorRate, apdex, apdexDelta = "n/a", "n/a", "n/a", "n/a", "n/a"
+		}
+		name := c.Name
+		if name == "" {
+			name = "-"
+		}
+		rows = append(rows, []string{
+			"[::b]" + c.Ident + "[::-]",
+			tview.Escape(name),
+			tview.TranslateANSI(status),
+			lastStatusCode,
+			tview.TranslateANSI(rt),
+			tview.TranslateANSI(errorRate),
+			tview.TranslateANSI(apdex),
+			tview.TranslateANSI(apdexDelta),
+			tview.TranslateANSI(apdexChart),
+		})
+		idents = append(idents, c.Ident)
+	}
+	recentTitle := strconv.Itoa(int(recent.Minutes())) + "M"
+	periodTitle := timeRange{Period: period}.title()
+	headers := []string{"ID", "NAME", "STATUS", "HTTP", "RT " + recentTitle, "ERRORS " + recentTitle, "APDEX", "APDEX Δ " + recentTitle, "APDEX " + periodTitle}
+	sortColumns := map[string]int{topSortStatus: 2, topSortResponseTime: 4, topSortErrors: 5, topSortApdex: 7}
+	headers[sortColumns[sortKey]] += " ▼"
+	fillDashboardTable(v.table, headers,
+		[]int{tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignRight, tview.AlignRight, tview.AlignRight, tview.AlignRight, tview.AlignRight, tview.AlignLeft},
+		rows, idents)
+	v.table.SetTitle(fmt.Sprintf(" TOP (%d) ", len(rows)))
+}
+
+func (v *topView) renderStatus() {
+	v.Lock()
+	defer v.Unlock()
+	var parts []string
+	if v.paused {
+		parts = append(parts, "[yellow]PAUSED[-]")
+	}
+	if !v.refreshed.IsZero() {
+		parts = append(parts, "updated "+v.refreshed.Format("15:04:05"))
+	} else if v.err == nil {
+		parts = append(parts, "loading...")
+	}
+	if v.err != nil {
+		parts = append(parts, "[red]"+tview.Escape(v.err.Error())+"[-]")
+	}
+	keys := "[::d]s sort · t period · p pause · r refresh · q quit[::-]"
+	v.status.SetText(strings.Join(parts, " · ") + "  " + keys)
+}
@@ -0,0 +1,838 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/fatih/color"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+)
+
+const (
+	uiTabChecks           = 0
+	uiTabIncidents        = 1
+	uiTabChannels         = 2
+	uiMinRefreshInterval  = 5
+	uiMetricsConcurrency  = 8
+	uiPageMain            = "main"
+	uiPageModal           = "modal"
+	uiFooterPageStatus    = "status"
+	uiFooterPageFilter    = "filter"
+	uiStatusMessageExpiry = 5 * time.Second
+)
+
+var uiTabNames = []string{"CHECKS", "INCIDENTS", "CHANNELS"}
+
+var uiPeriods = []string{periodHour, periodDay, periodWeek, periodMonth}
+
+// `ui` flags
+var (
+	uiFlagPeriod          string
+	uiFlagRefreshInterval int
+)
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+
+	uiCmd.Flags().StringVarP(&uiFlagPeriod, "period", "p", "day", "initial period of metrics and charts")
+	uiCmd.Flags().IntVar(&uiFlagRefreshInterval, "refresh_interval", 10, "how often to refresh data, in seconds")
+}
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Open the interactive dashboard",
+	Long: `
+Open the interactive dashboard with live check status, check charts, incidents and notification channels.
+
+Keys:
+  1, 2, 3, Tab    switch between checks, incidents and channels
+  ↑/↓, j/k        move in the list
+  Enter           scroll check detail; Esc to get back to the list
+  /               filter the list; Enter to keep the filter, Esc to clear it
+  t               switch period of metrics and charts
+  r               refresh now
+  p               pause or resume refreshing
+  a               attach a notification channel to the selected check
+  d               delete the selected check
+  q               quit
+`,
+	Example:           `  binocs ui --period week --refresh_interval 30`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		util.VerifyAuthenticated()
+
+		match, err := regexp.MatchString(validPeriodPattern, uiFlagPeriod)
+		if err != nil || !match {
+			handleErr(fmt.Errorf("Invalid period provided. Supported periods: hour, day, week, month"))
+		}
+		if uiFlagRefreshInterval < uiMinRefreshInterval {
+			handleErr(fmt.Errorf("Refresh interval must be at least %d seconds", uiMinRefreshInterval))
+		}
+		spin.Disable()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		d := newDashboard(uiFlagPeriod, time.Duration(uiFlagRefreshInterval)*time.Second)
+		err = d.run(ctx)
+		if err != nil {
+			handleErr(err)
+		}
+	},
+}
+
+// dashboard is the state of `binocs ui`; data fields are guarded by the mutex,
+// widgets are only touched from the tview event loop
+type dashboard struct {
+	sync.Mutex
+	period    string
+	interval  time.Duration
+	paused    bool
+	user      User
+	checks    []Check
+	metrics   map[string]MetricsResponse
+	incidents []Incident
+	channels  []Channel
+	refreshed time.Time
+	err       error
+
+	tab         int
+	filter      string
+	detailIdent string
+	message     string
+	messageTime time.Time
+	refreshNow  chan struct{}
+
+	app            *tview.Application
+	pages          *tview.Pages
+	tabs           *tview.TextView
+	content        *tview.Pages
+	checksTable    *tview.Table
+	detail         *tview.TextView
+	incidentsTable *tview.Table
+	channelsTable  *tview.Table
+	footer         *tview.Pages
+	status         *tview.TextView
+	filterInput    *tview.InputField
+}
+
+func newDashboard(period string, interval time.Duration) *dashboard {
+	d := &dashboard{
+		period:     period,
+		interval:   interval,
+		metrics:    map[string]MetricsResponse{},
+		refreshNow: make(chan struct{}, 1),
+	}
+	tview.Styles.PrimitiveBackgroundColor = tcell.ColorDefault
+	tview.Styles.ContrastBackgroundColor = tcell.ColorDefault
+	tview.Styles.PrimaryTextColor = tcell.ColorDefault
+
+	d.app = tview.NewApplication()
+	d.tabs = tview.NewTextView().SetDynamicColors(true).SetRegions(true)
+
+	d.checksTable = newDashboardTable(" CHECKS ")
+	d.checksTable.SetSelectionChangedFunc(func(row, column int) {
+		d.showDetail(d.selectedIdent(d.checksTable))
+	})
+	d.checksTable.SetSelectedFunc(func(row, column int) {
+		d.app.SetFocus(d.detail)
+	})
+	d.detail = tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(false)
+	d.detail.SetBorder(true).SetTitle(" DETAIL ")
+	d.detail.SetDoneFunc(func(key tcell.Key) {
+		d.app.SetFocus(d.checksTable)
+	})
+	checksPage := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.checksTable, 0, 2, true).
+		AddItem(d.detail, 0, 3, false)
+
+	d.incidentsTable = newDashboardTable(" INCIDENTS ")
+	d.channelsTable = newDashboardTable(" CHANNELS ")
+
+	d.content = tview.NewPages().
+		AddPage(uiTabNames[uiTabChecks], checksPage, true, true).
+		AddPage(uiTabNames[uiTabIncidents], d.incidentsTable, true, false).
+		AddPage(uiTabNames[uiTabChannels], d.channelsTable, true, false)
+
+	d.status = tview.NewTextView().SetDynamicColors(true)
+	d.filterInput = tview.NewInputField().SetLabel("/").SetFieldBackgroundColor(tcell.ColorDefault)
+	d.filterInput.SetChangedFunc(func(text string) {
+		d.filter = text
+		d.render()
+	})
+	d.filterInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			d.filterInput.SetText("")
+		}
+		d.footer.SwitchToPage(uiFooterPageStatus)
+		d.app.SetFocus(d.currentTable())
+		d.renderStatus()
+	})
+	d.footer = tview.NewPages().
+		AddPage(uiFooterPageStatus, d.status, true, true).
+		AddPage(uiFooterPageFilter, d.filterInput, true, false)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.tabs, 1, 0, false).
+		AddItem(d.content, 0, 1, true).
+		AddItem(d.footer, 1, 0, false)
+	d.pages = tview.NewPages().AddPage(uiPageMain, layout, true, true)
+	d.app.SetRoot(d.pages, true).SetFocus(d.checksTable)
+	d.app.SetInputCapture(d.handleKey)
+	d.renderTabs()
+	return d
+}
+
+func newDashboardTable(title string) *tview.Table {
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true).SetTitle(title)
+	table.SetSelectedStyle(tcell.StyleDefault.Reverse(true))
+	return table
+}
+
+func (d *dashboard) run(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		d.app.Stop()
+	}()
+	go d.refreshLoop(ctx)
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.app.QueueUpdateDraw(d.renderStatus)
+			}
+		}
+	}()
+	return d.app.Run()
+}
+
+func (d *dashboard) refreshLoop(ctx context.Context) {
+	d.refresh()
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Lock()
+			paused := d.paused
+			d.Unlock()
+			if !paused {
+				d.refresh()
+			}
+		case <-d.refreshNow:
+			d.refresh()
+			ticker.Reset(d.interval)
+		}
+	}
+}
+
+func (d *dashboard) requestRefresh() {
+	select {
+	case d.refreshNow <- struct{}{}:
+	default:
+	}
+}
+
+// refresh loads all dashboard data; on failure the previous data is kept and the error is shown in the footer
+func (d *dashboard) refresh() {
+	d.Lock()
+	period := d.period
+	d.Unlock()
+	user, checks, metrics, incidents, channels, err := loadDashboardData(period)
+	d.Lock()
+	d.err = err
+	if err == nil {
+		d.user, d.checks, d.metrics, d.incidents, d.channels = user, checks, metrics, incidents, channels
+		d.refreshed = time.Now()
+	}
+	d.Unlock()
+	d.app.QueueUpdateDraw(func() {
+		ident := d.detailIdent
+		d.render()
+		if d.detailIdent == ident {
+			d.showDetail(d.selectedIdent(d.checksTable))
+		}
+	})
+}
+
+func loadDashboardData(period string) (User, []Check, map[string]MetricsResponse, []Incident, []Channel, error) {
+	metrics := make(map[string]MetricsResponse)
+	user, err := fetchUser()
+	if err != nil {
+		return user, nil, metrics, nil, nil, err
+	}
+	checks, err := fetchChecks(url.Values{})
+	if err != nil {
+		return user, nil, metrics, nil, nil, err
+	}
+	incidentsURLValues := url.Values{}
+	timeRange{Period: period}.setURLValues(&incidentsURLValues, false)
+	incidents, err := fetchIncidents(incidentsURLValues)
+	if err != nil {
+		return user, checks, metrics, nil, nil, err
+	}
+	channels, err := fetchChannels(url.Values{})
+	if err != nil {
+		return user, checks, metrics, incidents, nil, err
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	queue := make(chan string)
+	for i := 0; i < uiMetricsConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ident := range queue {
+				m, err := fetchMetrics(ident, &url.Values{"period": []string{period}})
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				} else if err == nil {
+					metrics[ident] = m
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, c := range checks {
+		queue <- c.Ident
+	}
+	close(queue)
+	wg.Wait()
+	return user, checks, metrics, incidents, channels, firstErr
+}
+
+func (d *dashboard) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if d.pages.HasPage(uiPageModal) || d.app.GetFocus() == d.filterInput {
+		return event
+	}
+	switch event.Key() {
+	case tcell.KeyTab:
+		d.switchTab((d.tab + 1) % len(uiTabNames))
+		return nil
+	case tcell.KeyBacktab:
+		d.switchTab((d.tab + len(uiTabNames) - 1) % len(uiTabNames))
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+	switch event.Rune() {
+	case 'q':
+		d.app.Stop()
+	case '1', '2', '3':
+		d.switchTab(int(event.Rune() - '1'))
+	case '/':
+		d.footer.SwitchToPage(uiFooterPageFilter)
+		d.app.SetFocus(d.filterInput)
+	case 'r':
+		d.setMessage("refreshing...")
+		d.requestRefresh()
+	case 'p':
+		d.Lock()
+		d.paused = !d.paused
+		d.Unlock()
+		d.renderStatus()
+	case 't':
+		d.Lock()
+		for i, p := range uiPeriods {
+			if p == d.period {
+				d.period = uiPeriods[(i+1)%len(uiPeriods)]
+				break
+			}
+		}
+		d.Unlock()
+		d.detailIdent = ""
+		d.setMessage("loading " + d.period + " metrics...")
+		d.requestRefresh()
+	case 'a':
+		if ident := d.selectedIdent(d.checksTable); d.tab == uiTabChecks && len(ident) > 0 {
+			d.showAttachChannel(ident)
+		}
+	case 'd':
+		if ident := d.selectedIdent(d.checksTable); d.tab == uiTabChecks && len(ident) > 0 {
+			d.showDeleteCheck(ident)
+		}
+	case 'j':
+		return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+	case 'k':
+		return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+	default:
+		return event
+	}
+	return nil
+}
+
+func (d *dashboard) switchTab(tab int) {
+	d.tab = tab
+	d.content.SwitchToPage(uiTabNames[tab])
+	d.app.SetFocus(d.currentTable())
+	d.renderTabs()
+}
+
+func (d *dashboard) currentTable() *tview.Table {
+	switch d.tab {
+	case uiTabIncidents:
+		return d.incidentsTable
+	case uiTabChannels:
+		return d.channelsTable
+	}
+	return d.checksTable
+}
+
+func (d *dashboard) selectedIdent(table *tview.Table) string {
+	row, _ := table.GetSelection()
+	cell := table.GetCell(row, 0)
+	if ident, ok := cell.GetReference().(string); ok {
+		return ident
+	}
+	return ""
+}
+
+func (d *dashboard) setMessage(message string) {
+	d.message = message
+	d.messageTime = time.Now()
+	d.renderStatus()
+}
+
+func (d *dashboard) render() {
+	d.renderTabs()
+	d.renderChecks()
+	d.renderIncidents()
+	d.renderChannels()
+	d.renderStatus()
+}
+
+func (d *dashboard) renderTabs() {
+	var tabs []string
+	for i, name := range uiTabNames {
+		if i == d.tab {
+			tabs = append(tabs, fmt.Sprintf(`[::r] %d %s [::-]`, i+1, name))
+		} else {
+			tabs = append(tabs, fmt.Sprintf(` %d %s `, i+1, name))
+		}
+	}
+	d.Lock()
+	period := d.period
+	d.Unlock()
+	d.tabs.SetText(strings.Join(tabs, " ") + "  [::d]period: " + period + "[::-]")
+}
+
+func (d *dashboard) renderStatus() {
+	d.Lock()
+	defer d.Unlock()
+	var parts []string
+	if d.paused {
+		parts = append(parts, "[yellow]PAUSED[-]")
+	}
+	if !d.refreshed.IsZero() {
+		parts = append(parts, "updated "+d.refreshed.Format("15:04:05"))
+	} else if d.err == nil {
+		parts = append(parts, "loading...")
+	}
+	if d.user.CreditBalance == 0 && !d.refreshed.IsZero() {
+		parts = append(parts, "[red]zero credits, checks paused[-]")
+	}
+	if len(d.filter) > 0 {
+		parts = append(parts, "filter: "+tview.Escape(d.filter))
+	}
+	if d.err != nil {
+		parts = append(parts, "[red]"+tview.Escape(d.err.Error())+"[-]")
+	}
+	if len(d.message) > 0 && time.Since(d.messageTime) < uiStatusMessageExpiry {
+		parts = append(parts, tview.Escape(d.message))
+	}
+	keys := "[::d]p pause · r refresh · t period · / filter · a attach · d delete · q quit[::-]"
+	d.status.SetText(strings.Join(parts, " · ") + "  " + keys)
+}
+
+func (d *dashboard) matchesFilter(values ...string) bool {
+	if len(d.filter) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.Contains(strings.ToLower(v), strings.ToLower(d.filter)) {
+			return true
+		}
+	}
+	return false
+}
+
+// fillDashboardTable replaces table rows and keeps the selection on the row with the same ident
+func fillDashboardTable(table *tview.Table, headers []string, alignments []int, rows [][]string, idents []string) {
+	selected := ""
+	if row, _ := table.GetSelection(); row > 0 {
+		if ident, ok := table.GetCell(row, 0).GetReference().(string); ok {
+			selected = ident
+		}
+	}
+	table.Clear()
+	for i, h := range headers {
+		table.SetCell(0, i, tview.NewTableCell(h).SetAttributes(tcell.AttrBold).SetSelectable(false).SetAlign(alignments[i]))
+	}
+	selectRow := 1
+	for r, row := range rows {
+		for i, v := range row {
+			cell := tview.NewTableCell(v).SetAlign(alignments[i])
+			if i == 0 {
+				cell.SetReference(idents[r])
+			}
+			table.SetCell(r+1, i, cell)
+		}
+		if idents[r] == selected {
+			selectRow = r + 1
+		}
+	}
+	if row, _ := table.GetSelection(); len(rows) > 0 && row != selectRow {
+		table.Select(selectRow, 0)
+	}
+}
+
+func (d *dashboard) renderChecks() {
+	d.Lock()
+	checks := append([]Check{}, d.checks...)
+	metrics := d.metrics
+	zeroCredits := d.user.CreditBalance == 0
+	d.Unlock()
+	sort.Slice(checks, func(i, j int) bool {
+		return strings.ToLower(checks[i].Name) < strings.ToLower(checks[j].Name)
+	})
+	var rows [][]string
+	var idents []string
+	for _, c := range checks {
+		if !d.matchesFilter(c.Ident, c.Name, c.Resource) {
+			continue
+		}
+		m := metrics[c.Ident]
+		status := formatStatus(&c)
+		lastStatusCode := regexp.MustCompile(`^[1-5]{1}[0-9]{2}`).FindString(c.LastStatusCode)
+		if lastStatusCode == "" {
+			lastStatusCode = "-"
+		}
+		mrt, uptime, apdex := formatMRT(m.MRT), formatUptime(m.Uptime), formatApdex(m.Apdex)
+		if zeroCredits {
+			status = color.YellowString(statusName[statusUnknown])
+			lastStatusCode, mrt, uptime, apdex = "n/a", "n/a", "n/a", "n/a"
+		}
+		name := c.Name
+		if name == "" {
+			name = "-"
+		}
+		rows = append(rows, []string{
+			"[::b]" + c.Ident + "[::-]",
+			tview.Escape(name),
+			tview.Escape(util.Ellipsis(c.Resource, 40)),
+			tview.TranslateANSI(status),
+			lastStatusCode,
+			tview.TranslateANSI(mrt),
+			tview.TranslateANSI(uptime),
+			tview.TranslateANSI(apdex),
+		})
+		idents = append(idents, c.Ident)
+	}
+	fillDashboardTable(d.checksTable,
+		[]string{"ID", "NAME", "URL/HOST", "STATUS", "HTTP", "MRT", "UPTIME", "APDEX"},
+		[]int{tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignRight, tview.AlignRight, tview.AlignRight, tview.AlignRight},
+		rows, idents)
+	d.checksTable.SetTitle(fmt.Sprintf(" CHECKS (%d) ", len(rows)))
+	if len(rows) == 0 {
+		d.detailIdent = ""
+		d.detail.SetText("")
+	} else if ident := d.selectedIdent(d.checksTable); ident != d.detailIdent {
+		d.showDetail(ident)
+	}
+}
+
+func (d *dashboard) renderIncidents() {
+	d.Lock()
+	incidents := d.incidents
+	d.Unlock()
+	var rows [][]string
+	var idents []string
+	for _, v := range incidents {
+		if !d.matchesFilter(v.Ident, v.CheckIdent, v.CheckName, v.CheckResource, v.IncidentState) {
+			continue
+		}
+		state := strings.ToUpper(v.IncidentState)
+		switch v.IncidentState {
+		case incidentStateOpen:
+			state = "[yellow]" + state + "[-]"
+		case incidentStateResolved:
+			state = "[green]" + state + "[-]"
+		}
+		closed := v.Closed
+		if closed == "" {
+			closed = "-"
+		}
+		rows = append(rows, []string{
+			"[::b]" + v.Ident + "[::-]",
+			v.CheckIdent,
+			tview.Escape(v.CheckName),
+			state,
+			v.Opened,
+			closed,
+			util.OutputDurationWithDays(v.Duration),
+			tview.Escape(v.IncidentNote),
+		})
+		idents = append(idents, v.Ident)
+	}
+	fillDashboardTable(d.incidentsTable,
+		[]string{"INCIDENT ID", "CHECK ID", "CHECK NAME", "STATE", "OPENED", "CLOSED", "DURATION", "NOTE"},
+		[]int{tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignLeft},
+		rows, idents)
+	d.incidentsTable.SetTitle(fmt.Sprintf(" INCIDENTS (%d) ", len(rows)))
+}
+
+func (d *dashboard) renderChannels() {
+	d.Lock()
+	channels := d.channels
+	d.Unlock()
+	var rows [][]string
+	var idents []string
+	for _, v := range channels {
+		if !d.matchesFilter(v.Ident, v.Type, v.Alias, v.displayHandle()) {
+			continue
+		}
+		lastUsed := v.LastUsed
+		if v.UsedCount == 0 {
+			lastUsed = "n/a"
+		}
+		rows = append(rows, []string{
+			"[::b]" + v.Ident + "[::-]",
+			v.Type,
+			tview.Escape(v.Alias),
+			tview.Escape(v.displayHandle()),
+			fmt.Sprintf("%d check(s)", len(v.Checks)),
+			fmt.Sprintf("%d ×", v.UsedCount),
+			lastUsed,
+		})
+		idents = append(idents, v.Ident)
+	}
+	fillDashboardTable(d.channelsTable,
+		[]string{"ID", "TYPE", "ALIAS", "HANDLE", "ATTACHED", "USED", "LAST USED"},
+		[]int{tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignLeft, tview.AlignRight, tview.AlignLeft},
+		rows, idents)
+	d.channelsTable.SetTitle(fmt.Sprintf(" CHANNELS (%d) ", len(rows)))
+}
+
+// showDetail loads charts of the check in the background; results of a check
+// that is no longer selected are dropped
+func (d *dashboard) showDetail(ident string) {
+	if len(ident) == 0 {
+		return
+	}
+	d.Lock()
+	var check *Check
+	for i := range d.checks {
+		if d.checks[i].Ident == ident {
+			check = &d.checks[i]
+		}
+	}
+	if check == nil {
+		d.Unlock()
+		return
+	}
+	c, metrics, user, period := *check, d.metrics[ident], d.user, d.period
+	d.Unlock()
+	if d.detailIdent != ident {
+		d.detail.SetText("[::d]loading " + ident + "...[::-]").ScrollToBeginning()
+	}
+	d.detailIdent = ident
+	go func() {
+		text, err := composeCheckDetail(c, metrics, &user, period)
+		if err != nil {
+			text = color.RedString(err.Error())
+		}
+		d.app.QueueUpdateDraw(func() {
+			if d.detailIdent == ident {
+				row, column := d.detail.GetScrollOffset()
+				d.detail.SetText(tview.TranslateANSI(text)).ScrollTo(row, column)
+			}
+		})
+	}()
+}
+
+// composeCheckDetail renders check attributes and the charts of `check inspect`
+func composeCheckDetail(check Check, metrics MetricsResponse, user *User, period string) (string, error) {
+	tr := timeRange{Period: period}
+	urlValues := url.Values{}
+	tr.setURLValues(&urlValues, true)
+	periodTitle := tr.title()
+
+	name := check.Name
+	if name == "" {
+		name = "-"
+	}
+	status := formatStatus(&check)
+	if user.CreditBalance == 0 {
+		status = color.YellowString(statusName[statusUnknown])
+	}
+	lines := []string{
+		colorBold.Sprint("ID: ") + check.Ident + "    " + colorBold.Sprint("Name: ") + name + "    " + colorBold.Sprint("Status: ") + status,
+		colorBold.Sprint("URL/Host: ") + check.Resource,
+		colorBold.Sprint("Uptime: ") + formatUptime(metrics.Uptime) + "    " + colorBold.Sprint("Apdex: ") + formatApdex(metrics.Apdex) + "    " +
+			colorBold.Sprint("MRT: ") + formatMRT(metrics.MRT) + "    " + colorBold.Sprint("P50/90/95/99: ") +
+			formatPercentile(metrics.P50, check.Target) + " / " + formatPercentile(metrics.P90, check.Target) + " / " +
+			formatPercentile(metrics.P95, check.Target) + " / " + formatPercentile(metrics.P99, check.Target),
+		colorBold.Sprint("Regions: ") + strings.Join(getRegionAliasesByIds(check.Regions), ", ") + "    " +
+			colorBold.Sprint("Channels: ") + strconv.Itoa(len(check.Channels)),
+		"",
+	}
+
+	if check.Protocol == protocolHTTP || check.Protocol == protocolHTTPS {
+		responseCodes, err := fetchResponseCodes(check.Ident, &urlValues)
+		if err != nil {
+			return "", err
+		}
+		responseCodesChart := drawResponseCodesChart(responseCodes, nil, tr.dataPoints(), check.UpCodes, 16)
+		lines = append(lines, drawChartTitle("HTTP RESPONSE CODES", responseCodesChart, periodTitle), responseCodesChart, "")
+	}
+
+	apdex, err := fetchApdex(check.Ident, &urlValues)
+	if err != nil {
+		return "", err
+	}
+	apdexChart := drawApdexChart(apdex, nil, tr.dataPoints(), "      ")
+	lines = append(lines, drawChartTitle("APDEX TREND", apdexChart, periodTitle), apdexChart, "")
+
+	responseTimeData, err := util.BinocsAPI("/checks/"+check.Ident+"/response-time?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return "", err
+	}
+	responseTime := make([]ResponseTimeResponse, 0)
+	err = json.Unmarshal(responseTimeData, &responseTime)
+	if err != nil {
+		return "", err
+	}
+	responseTimeChart := drawResponseTimeChart(responseTime, tr.dataPoints(), check.Target, false, "")
+	lines = append(lines, drawChartTitle("RESPONSE TIME", responseTimeChart, periodTitle), responseTimeChart, "")
+
+	responseTimeHeatmap, err := fetchResponseTimeHeatmap(check.Ident, &urlValues)
+	if err != nil {
+		return "", err
+	}
+	responseTimeHeatmapChart := drawResponseTimeHeatmapChart(responseTimeHeatmap, tr.dataPoints(), check.Target, "")
+	lines = append(lines, drawChartTitle("RESPONSE TIME HEATMAP", responseTimeHeatmapChart, periodTitle), responseTimeHeatmapChart)
+	lines = append(lines, drawTimeline(user, tr, "                "))
+	return strings.Join(lines, "\n"), nil
+}
+
+func (d *dashboard) showModal(p tview.Primitive, width, height int) {
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+	d.pages.AddPage(uiPageModal, modal, true, true)
+	d.app.SetFocus(p)
+}
+
+func (d *dashboard) hideModal() {
+	d.pages.RemovePage(uiPageModal)
+	d.app.SetFocus(d.currentTable())
+}
+
+func (d *dashboard) showDeleteCheck(ident string) {
+	d.Lock()
+	var identity string
+	for _, c := range d.checks {
+		if c.Ident == ident {
+			identity = c.Identity()
+		}
+	}
+	d.Unlock()
+	modal := tview.NewModal().
+		SetText("Delete " + ident + " " + tview.Escape(identity) + " and its collected metrics?").
+		AddButtons([]string{"Cancel", "Delete"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			d.pages.RemovePage(uiPageModal)
+			d.app.SetFocus(d.currentTable())
+			if buttonLabel != "Delete" {
+				return
+			}
+			d.setMessage("deleting check " + ident + "...")
+			go func() {
+				_, err := util.BinocsAPI("/checks/"+ident, http.MethodDelete, []byte{})
+				d.app.QueueUpdateDraw(func() {
+					if err != nil {
+						d.setMessage("Error deleting check " + ident + ": " + err.Error())
+						return
+					}
+					d.setMessage("Check " + ident + " successfully deleted")
+				})
+				d.requestRefresh()
+			}()
+		})
+	d.pages.AddPage(uiPageModal, modal, true, true)
+	d.app.SetFocus(modal)
+}
+
+func (d *dashboard) showAttachChannel(ident string) {
+	d.Lock()
+	var attached []string
+	for _, c := range d.checks {
+		if c.Ident == ident {
+			attached = c.Channels
+		}
+	}
+	channels := d.channels
+	d.Unlock()
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(" ATTACH CHANNEL TO " + ident + " ")
+	for _, ch := range channels {
+		if util.StringInSlice(ch.Ident, attached) {
+			continue
+		}
+		channelIdent := ch.Ident
+		list.AddItem(ch.Ident+"  "+ch.Type+"  "+tview.Escape(ch.Identity()), "", 0, func() {
+			d.hideModal()
+			d.setMessage("attaching channel " + channelIdent + " to check " + ident + "...")
+			go func() {
+				postData, err := json.Marshal(ChannelAttachment{})
+				if err == nil {
+					_, err = util.BinocsAPI("/channels/"+channelIdent+"/check/"+ident, http.MethodPost, postData)
+				}
+				d.app.QueueUpdateDraw(func() {
+					if err != nil {
+						d.setMessage("Error attaching channel " + channelIdent + ": " + err.Error())
+						return
+					}
+					d.setMessage("Successfully attached channel " + channelIdent + " to check " + ident)
+				})
+				d.requestRefresh()
+			}()
+		})
+	}
+	if list.GetItemCount() == 0 {
+		d.setMessage("All channels are already attached to check " + ident)
+		return
+	}
+	list.SetDoneFunc(d.hideModal)
+	d.showModal(list, 60, list.GetItemCount()+2)
+}
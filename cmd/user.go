@@ -86,7 +86,7 @@ Display information about current Binocs user
 		timezone, err := time.LoadLocation(respJSON.Timezone)
 		if err != nil {
 			spin.Stop()
-			handleErr(fmt.Errorf("Unknown timezone " + respJSON.Timezone))
+			handleErr(fmt.Errorf("Unknown timezone %s", respJSON.Timezone))
 		}
 
 		tableCheckCellContent := colorBold.Sprint(`Name: `) + respJSON.Name + "\n" +
@@ -108,7 +108,7 @@ Display information about current Binocs user
 		table := composeTable(tableData, columnDefinitions)
 		spin.Stop()
 		if respJSON.CreditBalance == 0 {
-			printZeroCreditsWarning()
+			printZeroCreditsWarning(os.Stdout)
 		}
 		table.Render()
 	},
@@ -171,7 +171,7 @@ This command is interactive and asks user for parameters that were not provided
 
 		_, err = time.LoadLocation(flagTimezone)
 		if err != nil {
-			handleErr(fmt.Errorf("Invalid timezone " + flagTimezone))
+			handleErr(fmt.Errorf("Invalid timezone %s", flagTimezone))
 		} else if flagTimezone == "" {
 			prompt := &survey.Select{
 				Message: "Enter your timezone:",
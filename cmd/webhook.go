@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	util "github.com/automato-io/binocs-cli/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	validWebhookMethodPattern = `^(POST|PUT|PATCH)$`
+	validWebhookURLPattern    = `^https?:\/\/[^\s]+$`
+	webhookSignatureHeader    = "X-Binocs-Signature"
+	webhookTimestampHeader    = "X-Binocs-Timestamp"
+	webhookSignaturePrefix    = "sha256="
+	webhookSecretLength       = 32
+	webhookMaxPayloadLength   = 8192
+	webhookDefaultMethod      = http.MethodPost
+	webhookDefaultDeliveries  = 10
+	webhookDefaultTolerance   = 300
+)
+
+// WebhookConfig holds the settings of a webhook channel; the API never returns the secret
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Payload string            `json:"payload,omitempty"`
+	Secret  string            `json:"secret,omitempty"`
+}
+
+// WebhookDelivery comes from the API as a JSON
+type WebhookDelivery struct {
+	Time          string `json:"time"`
+	Event         string `json:"event"`
+	IncidentIdent string `json:"incident_ident"`
+	CheckIdent    string `json:"check_ident"`
+	Attempt       int    `json:"attempt"`
+	StatusCode    int    `json:"status_code"`
+	Error         string `json:"error,omitempty"`
+	Duration      string `json:"duration"`
+}
+
+// webhookPayloadData is the data payload templates are rendered with by Binocs; it is used to validate templates locally
+type webhookPayloadData struct {
+	Event    string
+	Incident struct {
+		Ident         string
+		State         string
+		Opened        string
+		Closed        string
+		Duration      string
+		Note          string
+		ResponseCodes []string
+	}
+	Check struct {
+		Ident    string
+		Name     string
+		Resource string
+		Protocol string
+		Method   string
+		Status   string
+	}
+}
+
+// `webhook verify` flags
+var (
+	webhookVerifyFlagSecret    string
+	webhookVerifyFlagSignature string
+	webhookVerifyFlagTimestamp string
+	webhookVerifyFlagBodyFile  string
+	webhookVerifyFlagTolerance int
+)
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.AddCommand(webhookVerifyCmd)
+
+	webhookVerifyCmd.Flags().StringVar(&webhookVerifyFlagSecret, "secret", "", "signing secret of the webhook channel")
+	webhookVerifyCmd.Flags().StringVar(&webhookVerifyFlagSignature, "signature", "", "value of the "+webhookSignatureHeader+" request header")
+	webhookVerifyCmd.Flags().StringVar(&webhookVerifyFlagTimestamp, "timestamp", "", "value of the "+webhookTimestampHeader+" request header")
+	webhookVerifyCmd.Flags().StringVar(&webhookVerifyFlagBodyFile, "body_file", "", "file with the request body; default stdin")
+	webhookVerifyCmd.Flags().IntVar(&webhookVerifyFlagTolerance, "tolerance", webhookDefaultTolerance, "maximum age of the request, in seconds; 0 to skip the check")
+	webhookVerifyCmd.Flags().SortFlags = false
+}
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Tools for receivers of webhook notifications",
+	Long: `
+Tools for receivers of webhook notifications.
+
+Webhook requests are signed with the channel's secret: the ` + webhookSignatureHeader + ` header is "sha256=" followed by
+the hex-encoded HMAC-SHA256 of the ` + webhookTimestampHeader + ` header value (Unix time), a dot, and the request body.
+`,
+	DisableAutoGenTag: true,
+}
+
+var webhookVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the signature of a webhook request",
+	Long: `
+Verify the signature of a webhook request, e.g. one captured while developing a receiver.
+
+Exits with status 0 if the signature is valid, 1 otherwise.
+`,
+	Example:           `  binocs webhook verify --secret $SECRET --signature "sha256=5d41..." --timestamp 1767225600 < body.json`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(webhookVerifyFlagSecret) == 0 || len(webhookVerifyFlagSignature) == 0 || len(webhookVerifyFlagTimestamp) == 0 {
+			handleErr(fmt.Errorf("Provide the --secret, --signature and --timestamp flags"))
+		}
+		var body []byte
+		var err error
+		if len(webhookVerifyFlagBodyFile) > 0 {
+			body, err = os.ReadFile(webhookVerifyFlagBodyFile)
+		} else {
+			body, err = io.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			handleErr(err)
+		}
+		err = verifyWebhookSignature(webhookVerifyFlagSecret, webhookVerifyFlagSignature, webhookVerifyFlagTimestamp, body,
+			time.Duration(webhookVerifyFlagTolerance)*time.Second, time.Now())
+		if err != nil {
+			handleErr(err)
+		}
+		fmt.Println("Signature is valid")
+	},
+}
+
+// webhookSignature is the value of the signature header of a request with the body, sent at timestamp
+func webhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifyWebhookSignature(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid timestamp provided: %s; use the Unix time from the %s header", timestamp, webhookTimestampHeader)
+	}
+	if !hmac.Equal([]byte(strings.ToLower(strings.TrimSpace(signature))), []byte(webhookSignature(secret, timestamp, body))) {
+		return fmt.Errorf("Signature is invalid")
+	}
+	skew := now.Sub(time.Unix(sent, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if tolerance > 0 && skew > tolerance {
+		return fmt.Errorf("Signature is valid, but the timestamp is %v off, more than the tolerance of %v", skew.Round(time.Second), tolerance)
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// composeWebhookConfig applies webhook flags over the current configuration of an updated channel;
+// a secret is generated for new webhooks unless one is provided, and reported with the second return value
+func composeWebhookConfig(update bool, current *WebhookConfig, handle, method string, headers []string, payload, payloadFile, secret string) (WebhookConfig, bool, error) {
+	var config WebhookConfig
+	if update && current == nil {
+		// updating without the current URL and settings would reset them, and rotate the secret
+		return config, false, fmt.Errorf("Cannot read the current webhook settings of the channel")
+	}
+	if update {
+		config = *current
+	} else {
+		config.URL = handle
+		config.Method = webhookDefaultMethod
+	}
+	if len(method) > 0 {
+		method = strings.ToUpper(method)
+		match, err := regexp.MatchString(validWebhookMethodPattern, method)
+		if err != nil || !match {
+			return config, false, fmt.Errorf("Invalid method provided. Supported methods: POST, PUT, PATCH")
+		}
+		config.Method = method
+	}
+	if len(headers) > 0 {
+		config.Headers = map[string]string{}
+		for _, h := range headers {
+			kv := strings.SplitN(h, "=", 2)
+			if len(kv) != 2 || len(strings.TrimSpace(kv[0])) == 0 {
+				return config, false, fmt.Errorf("Invalid header provided: %s; use e.g. \"Authorization=Bearer xyz\"", h)
+			}
+			config.Headers[http.CanonicalHeaderKey(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+		}
+	}
+	if len(payload) > 0 && len(payloadFile) > 0 {
+		return config, false, fmt.Errorf("Cannot use --payload together with --payload_file")
+	}
+	if len(payloadFile) > 0 {
+		data, err := os.ReadFile(payloadFile)
+		if err != nil {
+			return config, false, err
+		}
+		payload = string(data)
+	}
+	if len(payload) > 0 {
+		err := validateWebhookPayload(payload)
+		if err != nil {
+			return config, false, err
+		}
+		config.Payload = payload
+	}
+	var generated bool
+	if len(secret) > 0 {
+		config.Secret = secret
+	} else if !update {
+		var err error
+		config.Secret, err = generateWebhookSecret()
+		if err != nil {
+			return config, false, err
+		}
+		generated = true
+	}
+	return config, generated, nil
+}
+
+// webhookPayloadFuncs are the functions payload templates can use besides the text/template builtins;
+// json encodes a value, e.g. {{json .Check.Name}} is a quoted and escaped JSON string
+var webhookPayloadFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// validateWebhookPayload renders the payload template with sample data, so that unknown fields are reported early
+func validateWebhookPayload(payload string) error {
+	if len(payload) > webhookMaxPayloadLength {
+		return fmt.Errorf("Payload template must not be longer than %d characters", webhookMaxPayloadLength)
+	}
+	tpl, err := template.New("payload").Funcs(webhookPayloadFuncs).Option("missingkey=error").Parse(payload)
+	if err != nil {
+		return fmt.Errorf("Invalid payload template: %v", err)
+	}
+	err = tpl.Execute(io.Discard, webhookPayloadData{})
+	if err != nil {
+		return fmt.Errorf("Invalid payload template: %v", err)
+	}
+	return nil
+}
+
+func fetchWebhookDeliveries(ident string, urlValues url.Values) ([]WebhookDelivery, error) {
+	deliveries := make([]WebhookDelivery, 0)
+	respData, err := util.BinocsAPI("/channels/"+ident+"/deliveries?"+urlValues.Encode(), http.MethodGet, []byte{})
+	if err != nil {
+		return deliveries, err
+	}
+	decoder := json.NewDecoder(bytes.NewBuffer(respData))
+	err = decoder.Decode(&deliveries)
+	return deliveries, err
+}
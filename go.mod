@@ -1,6 +1,6 @@
 module github.com/automato-io/binocs-cli
 
-go 1.18
+go 1.24
 
 require (
 	github.com/AlecAivazis/survey/v2 v2.3.5
@@ -8,7 +8,6 @@ require (
 	github.com/automato-io/s3update v0.1.1-0.20220803155358-9b19ac4d3c8c
 	github.com/automato-io/tablewriter v0.0.6-0.20220722150333-396053a67945
 	github.com/briandowns/spinner v1.18.1
-	github.com/creack/pty v1.1.18
 	github.com/fatih/color v1.13.0
 	github.com/gdamore/tcell/v2 v2.5.1
 	github.com/getsentry/sentry-go v0.16.0
@@ -18,7 +17,7 @@ require (
 	github.com/spf13/cobra v1.5.0
 	github.com/spf13/viper v1.12.0
 	golang.org/x/term v0.0.0-20220722155259-a9ba230a4035
-
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -55,7 +54,6 @@ require (
 	golang.org/x/text v0.3.7 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace github.com/briandowns/spinner v1.18.1 => github.com/automato-io/spinner v1.18.2-0.20220728062523-8d8b981b151a
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -16,10 +17,20 @@ import (
 )
 
 const apiURLBase = "https://api.binocs.sh"
+const apiTimeout = 60 * time.Second
 const storageDir = ".binocs"
 const jwtFile = "auth.json"
 
-var binocsAPIAccessToken string
+// binocsAPIAccessToken is read from auth.json once, and then only replaced after a 401; long-running commands
+// make requests from many goroutines, so it is guarded by accessTokenMu, which also lets only one of them refresh it
+var (
+	accessTokenMu        sync.Mutex
+	binocsAPIAccessToken string
+	accessTokenLoaded    bool
+)
+
+// apiClient is shared by all API requests, so that repeated requests, e.g. in --watch mode, reuse connections
+var apiClient = &http.Client{Timeout: apiTimeout}
 
 // AuthResponse comes from the API
 type AuthResponse struct {
@@ -50,14 +61,12 @@ func handleWarn(msg string) {
 
 // BinocsAPI is a gateway to the binocs REST API
 func BinocsAPI(path, method string, data []byte) ([]byte, error) {
-	var err error
-	url, err := url.Parse(apiURLBase + path)
+	respBody, respStatusCode, err := BinocsAPIWithStatus(path, method, data)
 	if err != nil {
 		return []byte{}, err
 	}
-	respBody, respStatusCode, err := makeBinocsAPIRequest(url, method, data)
-	if err != nil {
-		return []byte{}, err
+	if respStatusCode >= http.StatusInternalServerError {
+		return []byte{}, fmt.Errorf("Binocs API responded with %d %s", respStatusCode, http.StatusText(respStatusCode))
 	}
 	if respStatusCode == http.StatusNotFound {
 		return []byte{}, fmt.Errorf("The requested resource does not exist")
@@ -66,35 +75,71 @@ func BinocsAPI(path, method string, data []byte) ([]byte, error) {
 		var apiErrorResponse ApiErrorResponse
 		err = json.Unmarshal(respBody, &apiErrorResponse)
 		if err != nil {
-			handleErr(err)
+			return []byte{}, fmt.Errorf("Binocs API responded with %d %s", respStatusCode, http.StatusText(respStatusCode))
 		}
-		return []byte{}, fmt.Errorf(apiErrorResponse.Status + `: ` + apiErrorResponse.Error)
+		return []byte{}, fmt.Errorf("%s: %s", apiErrorResponse.Status, apiErrorResponse.Error)
+	}
+	if respStatusCode == http.StatusUnauthorized {
+		return []byte{}, fmt.Errorf("Please login to your account using `binocs login` command.")
+	}
+	return respBody, nil
+}
+
+// BinocsAPIWithStatus makes the request with the access token refreshed on 401, like BinocsAPI,
+// but leaves it up to the caller to check the response status code
+func BinocsAPIWithStatus(path, method string, data []byte) ([]byte, int, error) {
+	url, err := url.Parse(apiURLBase + path)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+	accessToken, err := currentAccessToken()
+	if err != nil {
+		return []byte{}, 0, err
+	}
+	respBody, respStatusCode, err := makeBinocsAPIRequest(url, method, data, accessToken)
+	if err != nil {
+		return []byte{}, 0, err
 	}
 	if respStatusCode == http.StatusUnauthorized {
-		clientKey := viper.Get("client_key")
-		if clientKey == nil {
-			handleErr(fmt.Errorf("Cannot read Client Key"))
+		clientKey, ok := viper.Get("client_key").(string)
+		if !ok {
+			return []byte{}, 0, fmt.Errorf("Cannot read Client Key")
 		}
-		_ = BinocsAPIGetAccessToken(clientKey.(string))
-		respBody, respStatusCode, err = makeBinocsAPIRequest(url, method, data)
+		_ = refreshAccessToken(clientKey, accessToken)
+		accessToken, err = currentAccessToken()
 		if err != nil {
-			return []byte{}, err
-		}
-		if respStatusCode == http.StatusUnauthorized {
-			return []byte{}, fmt.Errorf("Please login to your account using `binocs login` command.")
+			return []byte{}, 0, err
 		}
+		return makeBinocsAPIRequest(url, method, data, accessToken)
 	}
-	return respBody, nil
+	return respBody, respStatusCode, nil
 }
 
 // BinocsAPIGetAccessToken attempts to get an access token via API and stores it
 func BinocsAPIGetAccessToken(clientKey string) error {
+	accessTokenMu.Lock()
+	defer accessTokenMu.Unlock()
+	return getAccessToken(clientKey)
+}
+
+// refreshAccessToken gets a new access token after stale got a 401, unless another request has done so meanwhile
+func refreshAccessToken(clientKey, stale string) error {
+	accessTokenMu.Lock()
+	defer accessTokenMu.Unlock()
+	if accessTokenLoaded && binocsAPIAccessToken != stale {
+		return nil
+	}
+	return getAccessToken(clientKey)
+}
+
+// getAccessToken must be called with accessTokenMu held
+func getAccessToken(clientKey string) error {
 	url, err := url.Parse(apiURLBase + "/authenticate")
 	if err != nil {
 		return err
 	}
 	postData := []byte("{\"client_key\": \"" + clientKey + "\"}")
-	respBody, respStatusCode, err := makeBinocsAPIRequest(url, http.MethodPost, postData)
+	respBody, respStatusCode, err := makeBinocsAPIRequest(url, http.MethodPost, postData, "")
 	if err != nil {
 		return err
 	}
@@ -110,11 +155,32 @@ func BinocsAPIGetAccessToken(clientKey string) error {
 	if err != nil {
 		return err
 	}
+	binocsAPIAccessToken = respJSON.AccessToken
+	accessTokenLoaded = true
 	return nil
 }
 
+func currentAccessToken() (string, error) {
+	accessTokenMu.Lock()
+	defer accessTokenMu.Unlock()
+	if !accessTokenLoaded {
+		accessToken, err := loadAccessToken()
+		if err != nil {
+			return "", err
+		}
+		binocsAPIAccessToken = accessToken
+		accessTokenLoaded = true
+	}
+	return binocsAPIAccessToken, nil
+}
+
 // ResetAccessToken removes the auth.json file that holds access_token
 func ResetAccessToken() error {
+	accessTokenMu.Lock()
+	defer accessTokenMu.Unlock()
+	binocsAPIAccessToken = ""
+	accessTokenLoaded = false
+
 	home, err := homedir.Dir()
 	if err != nil {
 		handleErr(err)
@@ -133,23 +199,18 @@ func VerifyAuthenticated() {
 	}
 }
 
-func makeBinocsAPIRequest(url *url.URL, method string, data []byte) ([]byte, int, error) {
+func makeBinocsAPIRequest(url *url.URL, method string, data []byte, accessToken string) ([]byte, int, error) {
 	req, err := http.NewRequest(method, url.String(), bytes.NewReader(data))
 	if err != nil {
 		return []byte{}, 0, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	binocsAPIAccessToken, err = loadAccessToken()
-	if err != nil {
-		return []byte{}, 0, err
+	if len(accessToken) > 0 {
+		req.Header.Set("Authorization", "bearer "+accessToken)
 	}
-	if len(binocsAPIAccessToken) > 0 {
-		req.Header.Set("Authorization", "bearer "+binocsAPIAccessToken)
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := apiClient.Do(req)
 	if err != nil {
-		handleErr(fmt.Errorf("Cannot reach Binocs API: %v\n", err))
+		return []byte{}, 0, fmt.Errorf("Cannot reach Binocs API: %v", err)
 	}
 	defer resp.Body.Close()
 	respBody, err := io.ReadAll(resp.Body)
@@ -162,7 +223,7 @@ func makeBinocsAPIRequest(url *url.URL, method string, data []byte) ([]byte, int
 func loadAccessToken() (string, error) {
 	home, err := homedir.Dir()
 	if err != nil {
-		handleErr(err)
+		return "", err
 	}
 	data, err := os.ReadFile(home + "/" + storageDir + "/" + jwtFile)
 	if err != nil {
@@ -182,7 +243,7 @@ func loadAccessToken() (string, error) {
 func storeAccessToken(d *AuthResponse) error {
 	home, err := homedir.Dir()
 	if err != nil {
-		handleErr(err)
+		return err
 	}
 
 	if _, err = os.Stat(home + "/" + storageDir + "/" + jwtFile); os.IsNotExist(err) {
@@ -192,5 +253,11 @@ func storeAccessToken(d *AuthResponse) error {
 		}
 	}
 	authContent := []byte("{\"access_token\": \"" + d.AccessToken + "\"}")
-	return os.WriteFile(home+"/"+storageDir+"/"+jwtFile, authContent, 0600)
+	// written aside and renamed, so that other binocs processes never read a half-written file
+	tmpPath := home + "/" + storageDir + "/" + jwtFile + ".tmp"
+	err = os.WriteFile(tmpPath, authContent, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, home+"/"+storageDir+"/"+jwtFile)
 }
@@ -0,0 +1,145 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const probeTimeout = 30 * time.Second
+
+// ProbeTimings holds durations of the individual request phases
+type ProbeTimings struct {
+	DNSLookup  time.Duration
+	Connection time.Duration
+	TLS        time.Duration
+	Wait       time.Duration
+	Transfer   time.Duration
+}
+
+// Total returns the sum of all request phases
+func (t ProbeTimings) Total() time.Duration {
+	return t.DNSLookup + t.Connection + t.TLS + t.Wait + t.Transfer
+}
+
+// ProbeResult is the outcome of a single local request
+type ProbeResult struct {
+	Status     string
+	StatusCode int
+	Timings    ProbeTimings
+	Timestamp  time.Time
+	Err        error
+}
+
+// ProbeHTTP makes a single HTTP(S) request and measures its phases using httptrace
+func ProbeHTTP(method, resource string) ProbeResult {
+	var dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsDone, wroteRequest, firstByte time.Time
+	result := ProbeResult{Timestamp: time.Now()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, resource, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	req.Header.Set("User-Agent", "binocs-cli")
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsDone = time.Now() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
+
+	// never reuse connections or follow redirects, every phase should be measured and the first response evaluated
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	transferDone := time.Now()
+
+	result.Status = resp.Status
+	result.StatusCode = resp.StatusCode
+	result.Timings.DNSLookup = sinceOrZero(dnsStart, dnsDone)
+	result.Timings.Connection = sinceOrZero(connectStart, connectDone)
+	result.Timings.TLS = sinceOrZero(tlsStart, tlsDone)
+	result.Timings.Wait = sinceOrZero(wroteRequest, firstByte)
+	result.Timings.Transfer = sinceOrZero(firstByte, transferDone)
+	return result
+}
+
+// ProbeTCP resolves the host and opens a TCP connection to HOST:PORT
+func ProbeTCP(resource string) ProbeResult {
+	result := ProbeResult{Timestamp: time.Now()}
+	hostPort := strings.TrimPrefix(resource, "tcp://")
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	dnsStart := time.Now()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if len(addrs) == 0 {
+		result.Err = fmt.Errorf("no addresses found for %s", host)
+		return result
+	}
+	result.Timings.DNSLookup = time.Since(dnsStart)
+
+	var dialer net.Dialer
+	connectStart := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(addrs[0], port))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Timings.Connection = time.Since(connectStart)
+	conn.Close()
+
+	result.Status = "Connected"
+	return result
+}
+
+func sinceOrZero(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}